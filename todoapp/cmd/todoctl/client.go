@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// client calls the todoapp REST API.
+type client struct {
+	base  *url.URL
+	token string
+	http  *http.Client
+}
+
+// request is one API call.
+type request struct {
+	method string
+	// path is relative to the server URL, such as "/todos/3".
+	path  string
+	query url.Values
+	// body, if not nil, is sent as JSON.
+	body any
+	// ifMatch is sent as the If-Match header if not empty.
+	ifMatch string
+}
+
+// apiError is an error response from the server.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%d %s)", e.Message, e.Status, http.StatusText(e.Status))
+}
+
+// do sends req and decodes the JSON response into out, unless out is
+// nil. It returns the raw response body as well, for JSON output.
+func (c *client) do(ctx context.Context, req request, out any) ([]byte, error) {
+	u := *c.base
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if req.body != nil {
+		data, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if req.body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if req.ifMatch != "" {
+		httpReq.Header.Set("If-Match", req.ifMatch)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		e := &apiError{Status: resp.StatusCode}
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &msg) == nil && msg.Error != "" {
+			e.Message = msg.Error
+		} else {
+			e.Message = strings.TrimSpace(string(data))
+		}
+		if resp.StatusCode == http.StatusUnauthorized && !strings.HasPrefix(req.path, "/auth/") {
+			e.Message += "; run todoctl login"
+		}
+		return nil, e
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, fmt.Errorf("unexpected response from %s: %w", u.Redacted(), err)
+		}
+	}
+	return data, nil
+}
+
+// tokenPath returns where login saves the token.
+func tokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "todoctl", "token"), nil
+}
+
+// loadToken returns the token saved by login.
+func loadToken() (string, error) {
+	path, err := tokenPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", errors.New("not logged in; run todoctl login EMAIL or set TODOCTL_TOKEN")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// saveToken saves token for later commands, readable only by the user.
+func saveToken(token string) (string, error) {
+	path, err := tokenPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, []byte(token+"\n"), 0o600)
+}
@@ -0,0 +1,484 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"todoapp/internal/todo"
+)
+
+// app runs the commands.
+type app struct {
+	client *client
+	// json selects JSON output instead of tables.
+	json bool
+	out  io.Writer
+}
+
+func (a *app) commands() map[string]func(ctx context.Context, args []string) error {
+	return map[string]func(ctx context.Context, args []string) error{
+		"login":  a.login,
+		"logout": a.logout,
+		"list":   a.list,
+		"add":    a.add,
+		"show":   a.show,
+		"edit":   a.edit,
+		"done":   a.done,
+		"rm":     a.rm,
+	}
+}
+
+// newFlagSet returns the flag set of a command.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet("todoctl "+name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: todoctl [flags] %s %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args with fs, allowing flags after the arguments,
+// and returns the arguments. Everything after "--" is an argument.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		if n := len(args) - fs.NArg(); n > 0 && args[n-1] == "--" {
+			return append(rest, fs.Args()...), nil
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+func (a *app) login(ctx context.Context, args []string) error {
+	fs := newFlagSet("login", "EMAIL")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "Password: ")
+	}
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && password != "") {
+		return fmt.Errorf("reading password: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	var resp todo.TokenResponse
+	data, err := a.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/auth/login",
+		body:   todo.CredentialsRequest{Email: args[0], Password: password},
+	}, &resp)
+	if err != nil {
+		return err
+	}
+	path, err := saveToken(resp.Token)
+	if err != nil {
+		return fmt.Errorf("saving token: %w", err)
+	}
+	if a.json {
+		return printJSON(a.out, data)
+	}
+	fmt.Fprintf(a.out, "Logged in as %s until %s; token saved to %s\n",
+		resp.User.Email, resp.ExpiresAt.Local().Format(timeLayout), path)
+	return nil
+}
+
+func (a *app) logout(ctx context.Context, args []string) error {
+	fs := newFlagSet("logout", "")
+	if args, err := parseFlags(fs, args); err != nil || len(args) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := newFlagSet("list", "")
+	completed := fs.String("completed", "", "only todos with this completion state: true or false")
+	list := fs.String("list", "", "only todos in this list `ID`, or inbox")
+	tags := fs.String("tags", "", "only todos with all of these comma-separated tags")
+	sort := fs.String("sort", "", "sort by id, created_at, completed_at, due_at, title, priority or position")
+	desc := fs.Bool("desc", false, "sort in descending order")
+	limit := fs.Int("limit", 0, "todos per page (default 50, max 500)")
+	all := fs.Bool("all", false, "fetch every page")
+	if args, err := parseFlags(fs, args); err != nil || len(args) != 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	q := url.Values{}
+	for name, v := range map[string]string{"completed": *completed, "list_id": *list, "tags": *tags, "sort": *sort} {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+	if *desc {
+		q.Set("order", "desc")
+	}
+	if *limit != 0 {
+		q.Set("limit", strconv.Itoa(*limit))
+	}
+
+	todos := []todo.Todo{}
+	for {
+		var page todo.Page
+		if _, err := a.client.do(ctx, request{method: http.MethodGet, path: "/todos", query: q}, &page); err != nil {
+			return err
+		}
+		todos = append(todos, page.Todos...)
+		if page.NextPageToken == "" {
+			break
+		}
+		if !*all {
+			fmt.Fprintln(os.Stderr, "There are more todos; use -all to list them all.")
+			break
+		}
+		q.Set("page_token", page.NextPageToken)
+	}
+
+	if a.json {
+		return encodeJSON(a.out, todos)
+	}
+	return printTable(a.out, todos)
+}
+
+func (a *app) add(ctx context.Context, args []string) error {
+	fs := newFlagSet("add", "TITLE...")
+	due := fs.String("due", "", "due `TIME`")
+	remind := fs.String("remind", "", "reminder `TIME`")
+	priority := fs.String("priority", "", "priority: none, low, medium, high or 0 to 3")
+	list := fs.String("list", "", "list `ID` to add the todo to")
+	parent := fs.String("parent", "", "`ID` of the todo to add a subtask to")
+	recurrence := fs.String("recurrence", "", "recurrence `RULE`, such as FREQ=WEEKLY;BYDAY=MO")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	req := todo.CreateTodoRequest{Title: strings.Join(args, " "), Recurrence: *recurrence}
+	if req.DueAt, err = parseTime("due", *due); err != nil {
+		return err
+	}
+	if req.RemindAt, err = parseTime("remind", *remind); err != nil {
+		return err
+	}
+	if *priority != "" {
+		if req.Priority, err = parsePriority(*priority); err != nil {
+			return err
+		}
+	}
+	if req.ListID, err = parseID("list", *list); err != nil {
+		return err
+	}
+	if req.ParentID, err = parseID("parent", *parent); err != nil {
+		return err
+	}
+
+	var t todo.Todo
+	data, err := a.client.do(ctx, request{method: http.MethodPost, path: "/todos", body: req}, &t)
+	if err != nil {
+		return err
+	}
+	return a.printTodo(t, data)
+}
+
+func (a *app) show(ctx context.Context, args []string) error {
+	fs := newFlagSet("show", "ID")
+	args, err := parseFlags(fs, args)
+	if err != nil || len(args) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	id, err := parseArgID(args[0])
+	if err != nil {
+		return err
+	}
+
+	var t todo.Todo
+	data, err := a.client.do(ctx, request{method: http.MethodGet, path: todoPath(id)}, &t)
+	if err != nil {
+		return err
+	}
+	if a.json {
+		return printJSON(a.out, data)
+	}
+	return printDetails(a.out, t)
+}
+
+func (a *app) edit(ctx context.Context, args []string) error {
+	fs := newFlagSet("edit", "ID")
+	title := fs.String("title", "", "new `TITLE`")
+	completed := fs.String("completed", "", "completion state: true or false")
+	due := fs.String("due", "", "due `TIME`, or empty to clear")
+	remind := fs.String("remind", "", "reminder `TIME`, or empty to clear")
+	priority := fs.String("priority", "", "priority: none, low, medium, high or 0 to 3")
+	list := fs.String("list", "", "list `ID`, or empty for the inbox")
+	parent := fs.String("parent", "", "parent todo `ID`, or empty for a top-level todo")
+	recurrence := fs.String("recurrence", "", "recurrence `RULE`, or empty to stop recurring")
+	args, err := parseFlags(fs, args)
+	if err != nil || len(args) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	id, err := parseArgID(args[0])
+	if err != nil {
+		return err
+	}
+
+	var req todo.UpdateTodoRequest
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if flagErr != nil {
+			return
+		}
+		switch f.Name {
+		case "title":
+			req.Title = title
+		case "completed":
+			var b bool
+			b, flagErr = strconv.ParseBool(*completed)
+			if flagErr != nil {
+				flagErr = errors.New("-completed must be true or false")
+			}
+			req.Completed = &b
+		case "due":
+			req.DueAt.Set = true
+			req.DueAt.Value, flagErr = parseTime("due", *due)
+		case "remind":
+			req.RemindAt.Set = true
+			req.RemindAt.Value, flagErr = parseTime("remind", *remind)
+		case "priority":
+			var p int
+			p, flagErr = parsePriority(*priority)
+			req.Priority = &p
+		case "list":
+			req.ListID.Set = true
+			req.ListID.Value, flagErr = parseID("list", *list)
+		case "parent":
+			req.ParentID.Set = true
+			req.ParentID.Value, flagErr = parseID("parent", *parent)
+		case "recurrence":
+			req.Recurrence = recurrence
+		}
+	})
+	if flagErr != nil {
+		return flagErr
+	}
+	if fs.NFlag() == 0 {
+		return errors.New("nothing to change; see todoctl edit -h")
+	}
+
+	var t todo.Todo
+	data, err := a.client.do(ctx, request{method: http.MethodPut, path: todoPath(id), body: req}, &t)
+	if err != nil {
+		return err
+	}
+	return a.printTodo(t, data)
+}
+
+func (a *app) done(ctx context.Context, args []string) error {
+	fs := newFlagSet("done", "ID...")
+	cascade := fs.Bool("cascade", false, "also complete the todos' subtasks")
+	args, err := parseFlags(fs, args)
+	if err != nil || len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	ids, err := parseArgIDs(args)
+	if err != nil {
+		return err
+	}
+
+	var todos []todo.Todo
+	var errs []error
+	for _, id := range ids {
+		t, err := a.complete(ctx, id, *cascade)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("todo %d: %w", id, err))
+			continue
+		}
+		todos = append(todos, t)
+	}
+
+	if a.json {
+		if todos == nil {
+			todos = []todo.Todo{}
+		}
+		if err := encodeJSON(a.out, todos); err != nil {
+			return err
+		}
+	} else if len(todos) > 0 {
+		if err := printTable(a.out, todos); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// complete toggles todo id unless it is already done. The toggle only
+// applies to the version just read, so a todo completed by someone else
+// in between is not reopened.
+func (a *app) complete(ctx context.Context, id int, cascade bool) (todo.Todo, error) {
+	var t todo.Todo
+	if _, err := a.client.do(ctx, request{method: http.MethodGet, path: todoPath(id)}, &t); err != nil {
+		return todo.Todo{}, err
+	}
+	if t.Completed {
+		return t, nil
+	}
+	q := url.Values{}
+	if cascade {
+		q.Set("cascade", "true")
+	}
+	_, err := a.client.do(ctx, request{
+		method:  http.MethodPost,
+		path:    todoPath(id) + "/toggle",
+		query:   q,
+		ifMatch: `"` + strconv.FormatInt(t.Version, 10) + `"`,
+	}, &t)
+	return t, err
+}
+
+func (a *app) rm(ctx context.Context, args []string) error {
+	fs := newFlagSet("rm", "ID...")
+	args, err := parseFlags(fs, args)
+	if err != nil || len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	ids, err := parseArgIDs(args)
+	if err != nil {
+		return err
+	}
+
+	type removed struct {
+		ID int `json:"id"`
+	}
+	deleted := []removed{}
+	var errs []error
+	for _, id := range ids {
+		if _, err := a.client.do(ctx, request{method: http.MethodDelete, path: todoPath(id)}, nil); err != nil {
+			errs = append(errs, fmt.Errorf("todo %d: %w", id, err))
+			continue
+		}
+		deleted = append(deleted, removed{ID: id})
+		if !a.json {
+			fmt.Fprintf(a.out, "Moved todo %d to the trash\n", id)
+		}
+	}
+	if a.json {
+		if err := encodeJSON(a.out, deleted); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// printTodo prints one todo a command returned: the server's JSON, or a
+// one-row table.
+func (a *app) printTodo(t todo.Todo, data []byte) error {
+	if a.json {
+		return printJSON(a.out, data)
+	}
+	return printTable(a.out, []todo.Todo{t})
+}
+
+func todoPath(id int) string {
+	return "/todos/" + strconv.Itoa(id)
+}
+
+func parseArgID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid todo id %q", s)
+	}
+	return id, nil
+}
+
+func parseArgIDs(args []string) ([]int, error) {
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := parseArgID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// parseID parses the value of an id flag; empty means none. For -list,
+// "inbox" means none too.
+func parseID(name, s string) (*int, error) {
+	if s == "" || (name == "list" && s == "inbox") {
+		return nil, nil
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("-%s must be an id", name)
+	}
+	return &id, nil
+}
+
+// parseTime parses the value of a time flag: RFC 3339, or a date for
+// midnight local time. Empty means none.
+func parseTime(name, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return &t, nil
+	}
+	return nil, fmt.Errorf("-%s must be an RFC 3339 time or a date (YYYY-MM-DD)", name)
+}
+
+var priorityNames = []string{"none", "low", "medium", "high"}
+
+func parsePriority(s string) (int, error) {
+	for p, name := range priorityNames {
+		if strings.EqualFold(s, name) || s == strconv.Itoa(p) {
+			return p, nil
+		}
+	}
+	return 0, errors.New("-priority must be none, low, medium, high or 0 to 3")
+}
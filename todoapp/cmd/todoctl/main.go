@@ -0,0 +1,138 @@
+// Command todoctl manages todos through the todoapp REST API.
+//
+// Usage:
+//
+//	todoctl [flags] login EMAIL
+//	todoctl [flags] logout
+//	todoctl [flags] list [-completed BOOL] [-list ID|inbox] [-tags A,B] [-sort FIELD] [-desc] [-limit N] [-all]
+//	todoctl [flags] add [-due TIME] [-remind TIME] [-priority N] [-list ID] [-parent ID] [-recurrence RULE] TITLE...
+//	todoctl [flags] show ID
+//	todoctl [flags] edit ID [-title TITLE] [-due TIME] [-remind TIME] [-priority N] [-list ID] [-parent ID] [-recurrence RULE]
+//	todoctl [flags] done [-cascade] ID...
+//	todoctl [flags] rm ID...
+//
+// login asks for the password on standard input and saves the token in
+// the user's config directory, where the other commands find it unless
+// -token or TODOCTL_TOKEN names another. done completes todos through the
+// toggle endpoint, leaving todos that are already done alone. edit only
+// changes the fields given; an empty -due, -remind, -list, -parent or
+// -recurrence clears it. rm moves todos to the trash.
+//
+// Times are RFC 3339 or YYYY-MM-DD, which is midnight local time.
+// Command flags may come before or after the arguments.
+//
+// Flags:
+//
+//	-server URL    todoapp base URL (TODOCTL_SERVER, default http://localhost:8081)
+//	-token TOKEN   API token (TODOCTL_TOKEN, default the one saved by login)
+//	-o FORMAT      output: table (default) or json
+//	-timeout D     timeout for each request (default 30s)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const defaultServer = "http://localhost:8081"
+
+// errUsage reports a command line that does not parse; the command's
+// usage has already been printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	fs := flag.NewFlagSet("todoctl", flag.ContinueOnError)
+	fs.Usage = usage
+	server := fs.String("server", envOr("TODOCTL_SERVER", defaultServer), "todoapp base URL")
+	token := fs.String("token", os.Getenv("TODOCTL_TOKEN"), "API token")
+	output := fs.String("o", "table", "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "timeout for each request")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+	if fs.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "todoctl: -o must be table or json\n")
+		os.Exit(2)
+	}
+
+	base, err := url.Parse(strings.TrimSuffix(*server, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		fmt.Fprintf(os.Stderr, "todoctl: -server must be an http or https URL\n")
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	app := &app{
+		client: &client{base: base, token: *token, http: &http.Client{Timeout: *timeout}},
+		json:   *output == "json",
+		out:    os.Stdout,
+	}
+	cmd, args := fs.Arg(0), fs.Args()[1:]
+	run, ok := app.commands()[cmd]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "todoctl: unknown command %q\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+	if cmd != "login" && cmd != "logout" && app.client.token == "" {
+		if app.client.token, err = loadToken(); err != nil {
+			fmt.Fprintf(os.Stderr, "todoctl: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := run(ctx, args); err != nil {
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "todoctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage: todoctl [flags] COMMAND [args]
+
+Commands:
+  login EMAIL      log in and save the token
+  logout           forget the saved token
+  list             list todos
+  add TITLE...     create a todo
+  show ID          show a todo
+  edit ID          change a todo
+  done ID...       complete todos
+  rm ID...         move todos to the trash
+
+Flags:
+  -server URL      todoapp base URL (TODOCTL_SERVER, default %s)
+  -token TOKEN     API token (TODOCTL_TOKEN, default the one saved by login)
+  -o FORMAT        output: table (default) or json
+  -timeout D       timeout for each request (default 30s)
+
+Run todoctl COMMAND -h for the flags of a command.
+`, defaultServer)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
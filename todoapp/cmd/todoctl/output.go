@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"todoapp/internal/todo"
+)
+
+// timeLayout is how times are shown in tables, in local time.
+const timeLayout = "2006-01-02 15:04"
+
+// printTable prints todos as a table with one row per todo.
+func printTable(w io.Writer, todos []todo.Todo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDONE\tPRIORITY\tDUE\tLIST\tTITLE\tTAGS")
+	for _, t := range todos {
+		done := "[ ]"
+		if t.Completed {
+			done = "[x]"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, done, priorityName(t.Priority), formatTime(t.DueAt), formatList(t.ListID), t.Title, formatTags(t.Tags))
+	}
+	return tw.Flush()
+}
+
+// printDetails prints every field of one todo, one per line.
+func printDetails(w io.Writer, t todo.Todo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	rows := []struct{ name, value string }{
+		{"ID", strconv.Itoa(t.ID)},
+		{"Title", t.Title},
+		{"Completed", strconv.FormatBool(t.Completed)},
+		{"Created", formatTime(&t.CreatedAt)},
+		{"Completed at", formatTime(t.CompletedAt)},
+		{"Due", formatTime(t.DueAt)},
+		{"Remind", formatTime(t.RemindAt)},
+		{"Priority", priorityName(t.Priority)},
+		{"List", formatList(t.ListID)},
+		{"Parent", formatID(t.ParentID)},
+		{"Recurrence", t.Recurrence},
+		{"Tags", formatTags(t.Tags)},
+		{"Version", strconv.FormatInt(t.Version, 10)},
+	}
+	if t.Progress != nil {
+		rows = append(rows, struct{ name, value string }{"Subtasks", fmt.Sprintf("%d of %d done", t.Progress.Completed, t.Progress.Total)})
+	}
+	for _, row := range rows {
+		if row.value != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", row.name, row.value)
+		}
+	}
+	return tw.Flush()
+}
+
+// printJSON prints a JSON response body indented.
+func printJSON(w io.Writer, data []byte) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// encodeJSON prints v as indented JSON.
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func priorityName(p int) string {
+	if p > 0 && p < len(priorityNames) {
+		return priorityNames[p]
+	}
+	return ""
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format(timeLayout)
+}
+
+func formatList(id *int) string {
+	if id == nil {
+		return "inbox"
+	}
+	return strconv.Itoa(*id)
+}
+
+func formatID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
+
+func formatTags(tags []todo.Tag) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return strings.Join(names, ",")
+}
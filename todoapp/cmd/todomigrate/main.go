@@ -0,0 +1,131 @@
+// Command todomigrate applies, rolls back or reports the todoapp schema
+// migrations.
+//
+// Usage:
+//
+//	todomigrate [flags] up
+//	todomigrate [flags] down [N]
+//	todomigrate [flags] status
+//	todomigrate [flags] assign-owner EMAIL
+//
+// assign-owner gives the todos, lists and tags created before user
+// accounts existed to the registered user with the given email.
+//
+// The database is configured the same way as todoapp: through -config,
+// TODOAPP_* environment variables or flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"todoapp/internal/config"
+	"todoapp/internal/migrate"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	cfg, args, err := config.Load(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	poolConfig, err := cfg.PoolConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		log.Fatal("Database connection failed:", err)
+	}
+	defer dbpool.Close()
+
+	migrator, err := migrate.New(dbpool)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid step count %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, applied)
+		}
+
+	case "assign-owner":
+		if len(args) != 2 {
+			usage()
+			os.Exit(2)
+		}
+		counts, err := migrator.AssignOwner(ctx, strings.ToLower(strings.TrimSpace(args[1])))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, table := range []string{"todos", "lists", "tags"} {
+			fmt.Printf("assigned %d %s\n", counts[table], table)
+		}
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [flags] up | down [N] | status | assign-owner EMAIL\n", os.Args[0])
+}
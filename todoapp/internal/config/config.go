@@ -0,0 +1,342 @@
+// Package config loads the todoapp runtime configuration.
+//
+// Every setting can come from a JSON config file, an environment variable
+// or a command-line flag. Later sources override earlier ones:
+//
+//	defaults < config file < environment < flags
+//
+// A setting named listen_addr is read from the "listen_addr" key of the
+// config file, the TODOAPP_LISTEN_ADDR environment variable and the
+// -listen-addr flag. The config file itself is named by -config or
+// TODOAPP_CONFIG.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const envPrefix = "TODOAPP_"
+
+// Config is the effective todoapp configuration.
+type Config struct {
+	Storage     string
+	DatabaseURL string
+
+	ListenAddr   string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// GRPCAddr is where the gRPC API listens. Empty disables it.
+	GRPCAddr string
+
+	// QueryTimeout bounds each repository operation. Zero disables the
+	// extra deadline; queries are still cancelled with their request.
+	QueryTimeout time.Duration
+
+	// AuthSecret signs API tokens. If empty, a random secret is generated
+	// at startup and tokens do not survive a restart.
+	AuthSecret string
+	// TokenTTL is how long an issued token stays valid.
+	TokenTTL time.Duration
+
+	// ReminderInterval is how often due reminders are checked. Zero
+	// disables the reminder scheduler.
+	ReminderInterval time.Duration
+
+	// TrashRetention is how long deleted todos stay in the trash before
+	// they are purged. PurgeInterval is how often the trash is purged;
+	// zero disables purging.
+	TrashRetention time.Duration
+	PurgeInterval  time.Duration
+
+	// Pool sizing. Zero values leave the pgxpool defaults in place.
+	PoolMaxConns          int32
+	PoolMinConns          int32
+	PoolMaxConnLifetime   time.Duration
+	PoolMaxConnIdleTime   time.Duration
+	PoolHealthCheckPeriod time.Duration
+}
+
+// Default returns the configuration used when nothing is overridden.
+func Default() Config {
+	return Config{
+		Storage:      "postgres",
+		DatabaseURL:  "postgres://postgres@localhost:5432/postgres?sslmode=disable",
+		ListenAddr:   ":8081",
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		GRPCAddr:     ":9090",
+		QueryTimeout: 3 * time.Second,
+
+		TokenTTL: 24 * time.Hour,
+
+		ReminderInterval: 30 * time.Second,
+
+		TrashRetention: 30 * 24 * time.Hour,
+		PurgeInterval:  time.Hour,
+	}
+}
+
+// setting binds one configuration key to a Config field.
+type setting struct {
+	key    string
+	usage  string
+	secret bool
+	ptr    any // *string, *int32 or *time.Duration
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "storage", usage: "todo storage backend: postgres or memory", ptr: &c.Storage},
+		{key: "database_url", usage: "Postgres connection URL", secret: true, ptr: &c.DatabaseURL},
+		{key: "listen_addr", usage: "HTTP listen address", ptr: &c.ListenAddr},
+		{key: "read_timeout", usage: "HTTP server read timeout", ptr: &c.ReadTimeout},
+		{key: "write_timeout", usage: "HTTP server write timeout", ptr: &c.WriteTimeout},
+		{key: "grpc_addr", usage: "gRPC listen address (empty = disabled)", ptr: &c.GRPCAddr},
+		{key: "query_timeout", usage: "deadline for each database operation (0 = none)", ptr: &c.QueryTimeout},
+		{key: "auth_secret", usage: "secret for signing API tokens, at least 32 bytes (default random per process)", secret: true, ptr: &c.AuthSecret},
+		{key: "token_ttl", usage: "lifetime of issued API tokens", ptr: &c.TokenTTL},
+		{key: "reminder_interval", usage: "how often to check for due reminders (0 = disabled)", ptr: &c.ReminderInterval},
+		{key: "trash_retention", usage: "how long deleted todos stay in the trash", ptr: &c.TrashRetention},
+		{key: "purge_interval", usage: "how often to purge expired todos from the trash (0 = disabled)", ptr: &c.PurgeInterval},
+		{key: "pool_max_conns", usage: "maximum database pool connections (0 = pgx default)", ptr: &c.PoolMaxConns},
+		{key: "pool_min_conns", usage: "minimum idle database pool connections", ptr: &c.PoolMinConns},
+		{key: "pool_max_conn_lifetime", usage: "maximum lifetime of a pooled connection (0 = pgx default)", ptr: &c.PoolMaxConnLifetime},
+		{key: "pool_max_conn_idle_time", usage: "maximum idle time of a pooled connection (0 = pgx default)", ptr: &c.PoolMaxConnIdleTime},
+		{key: "pool_health_check_period", usage: "interval between pool health checks (0 = pgx default)", ptr: &c.PoolHealthCheckPeriod},
+	}
+}
+
+func flagName(key string) string { return strings.ReplaceAll(key, "_", "-") }
+func envName(key string) string  { return envPrefix + strings.ToUpper(key) }
+
+// Load builds the configuration from defaults, the optional config file,
+// the environment and the given command-line arguments, then validates
+// it. It returns the arguments left over after flag parsing.
+func Load(name string, args []string) (Config, []string, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	// Flags have the highest precedence, so collect them first and apply
+	// them last.
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "path to a JSON config file")
+	flagged := make(map[string]string)
+	for _, s := range settings {
+		key := s.key
+		fs.Func(flagName(key), fmt.Sprintf("%s (env %s)", s.usage, envName(key)), func(v string) error {
+			flagged[key] = v
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		for _, s := range settings {
+			if v, ok := values[s.key]; ok {
+				if err := set(s.ptr, v); err != nil {
+					return Config{}, nil, fmt.Errorf("config file %s: %s: %w", *configFile, s.key, err)
+				}
+				delete(values, s.key)
+			}
+		}
+		for key := range values {
+			return Config{}, nil, fmt.Errorf("config file %s: unknown setting %q", *configFile, key)
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := os.LookupEnv(envName(s.key)); ok {
+			if err := set(s.ptr, v); err != nil {
+				return Config{}, nil, fmt.Errorf("%s: %w", envName(s.key), err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if v, ok := flagged[s.key]; ok {
+			if err := set(s.ptr, v); err != nil {
+				return Config{}, nil, fmt.Errorf("-%s: %w", flagName(s.key), err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// readFile reads a flat JSON object of settings. Values may be strings,
+// numbers or booleans; they are parsed the same way as environment values.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			values[k] = v
+		case float64, bool:
+			values[k] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("config file %s: %s must be a string, number or boolean", path, k)
+		}
+	}
+	return values, nil
+}
+
+func set(ptr any, v string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = v
+	case *int32:
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", v)
+		}
+		*p = int32(n)
+	case *time.Duration:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", v)
+		}
+		*p = d
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", ptr))
+	}
+	return nil
+}
+
+// Validate reports the first invalid setting, if any.
+func (c Config) Validate() error {
+	switch c.Storage {
+	case "postgres":
+		if c.DatabaseURL == "" {
+			return errors.New("database_url is required for postgres storage")
+		}
+	case "memory":
+	default:
+		return fmt.Errorf("storage must be postgres or memory, got %q", c.Storage)
+	}
+	if c.ListenAddr == "" {
+		return errors.New("listen_addr is required")
+	}
+	if c.ReadTimeout <= 0 || c.WriteTimeout <= 0 {
+		return errors.New("read_timeout and write_timeout must be positive")
+	}
+	if c.QueryTimeout < 0 {
+		return errors.New("query_timeout must not be negative")
+	}
+	if c.AuthSecret != "" && len(c.AuthSecret) < 32 {
+		return errors.New("auth_secret must be at least 32 bytes")
+	}
+	if c.TokenTTL <= 0 {
+		return errors.New("token_ttl must be positive")
+	}
+	if c.ReminderInterval < 0 {
+		return errors.New("reminder_interval must not be negative")
+	}
+	if c.TrashRetention <= 0 {
+		return errors.New("trash_retention must be positive")
+	}
+	if c.PurgeInterval < 0 {
+		return errors.New("purge_interval must not be negative")
+	}
+	if c.PoolMaxConns < 0 || c.PoolMinConns < 0 {
+		return errors.New("pool_max_conns and pool_min_conns must not be negative")
+	}
+	if c.PoolMaxConns > 0 && c.PoolMinConns > c.PoolMaxConns {
+		return errors.New("pool_min_conns must not exceed pool_max_conns")
+	}
+	if c.PoolMaxConnLifetime < 0 || c.PoolMaxConnIdleTime < 0 || c.PoolHealthCheckPeriod < 0 {
+		return errors.New("pool durations must not be negative")
+	}
+	return nil
+}
+
+// PoolConfig returns the pgxpool configuration for DatabaseURL with the
+// pool sizing settings applied.
+func (c Config) PoolConfig() (*pgxpool.Config, error) {
+	pc, err := pgxpool.ParseConfig(c.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database_url: %w", err)
+	}
+	if c.PoolMaxConns > 0 {
+		pc.MaxConns = c.PoolMaxConns
+	}
+	if c.PoolMinConns > 0 {
+		pc.MinConns = c.PoolMinConns
+	}
+	if c.PoolMaxConnLifetime > 0 {
+		pc.MaxConnLifetime = c.PoolMaxConnLifetime
+	}
+	if c.PoolMaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = c.PoolMaxConnIdleTime
+	}
+	if c.PoolHealthCheckPeriod > 0 {
+		pc.HealthCheckPeriod = c.PoolHealthCheckPeriod
+	}
+	return pc, nil
+}
+
+// String renders the effective configuration, one setting per line, with
+// secrets redacted. It is safe to log.
+func (c Config) String() string {
+	var b strings.Builder
+	for _, s := range c.settings() {
+		var v string
+		switch p := s.ptr.(type) {
+		case *string:
+			v = *p
+		case *int32:
+			v = strconv.Itoa(int(*p))
+		case *time.Duration:
+			v = p.String()
+		}
+		if s.secret {
+			v = redact(v)
+		}
+		fmt.Fprintf(&b, "%s = %s\n", s.key, v)
+	}
+	return b.String()
+}
+
+// redact hides the password in a connection URL. Values that are not
+// URLs are hidden entirely.
+func redact(v string) string {
+	if v == "" {
+		return v
+	}
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" {
+		return "<redacted>"
+	}
+	if q := u.Query(); q.Has("password") {
+		q.Set("password", "xxxxx")
+		u.RawQuery = q.Encode()
+	}
+	return u.Redacted()
+}
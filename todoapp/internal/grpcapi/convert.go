@@ -0,0 +1,142 @@
+package grpcapi
+
+import (
+	"time"
+
+	"todoapp/internal/grpcapi/todov1"
+	"todoapp/internal/todo"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// toProto converts a todo to its protobuf form.
+func toProto(t todo.Todo) *todov1.Todo {
+	p := &todov1.Todo{
+		Id:          int64(t.ID),
+		OwnerId:     int64(t.OwnerID),
+		Title:       t.Title,
+		Completed:   t.Completed,
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		CompletedAt: timestampOf(t.CompletedAt),
+		DueAt:       timestampOf(t.DueAt),
+		RemindAt:    timestampOf(t.RemindAt),
+		Priority:    int32(t.Priority),
+		Position:    t.Position,
+		ListId:      int64Ptr(t.ListID),
+		ParentId:    int64Ptr(t.ParentID),
+		Recurrence:  t.Recurrence,
+		Version:     t.Version,
+	}
+	for _, tag := range t.Tags {
+		p.Tags = append(p.Tags, &todov1.Tag{Id: int64(tag.ID), Name: tag.Name})
+	}
+	if t.Progress != nil {
+		p.Progress = &todov1.Progress{Completed: int32(t.Progress.Completed), Total: int32(t.Progress.Total)}
+	}
+	return p
+}
+
+var eventActions = map[todo.EventAction]todov1.TodoEvent_Action{
+	todo.EventCreate:  todov1.TodoEvent_ACTION_CREATE,
+	todo.EventUpdate:  todov1.TodoEvent_ACTION_UPDATE,
+	todo.EventToggle:  todov1.TodoEvent_ACTION_TOGGLE,
+	todo.EventDelete:  todov1.TodoEvent_ACTION_DELETE,
+	todo.EventRestore: todov1.TodoEvent_ACTION_RESTORE,
+	todo.EventMove:    todov1.TodoEvent_ACTION_MOVE,
+	todo.EventPurge:   todov1.TodoEvent_ACTION_PURGE,
+
+	todo.EventListCreate: todov1.TodoEvent_ACTION_LIST_CREATE,
+	todo.EventListUpdate: todov1.TodoEvent_ACTION_LIST_UPDATE,
+	todo.EventListDelete: todov1.TodoEvent_ACTION_LIST_DELETE,
+	todo.EventTagCreate:  todov1.TodoEvent_ACTION_TAG_CREATE,
+	todo.EventTagUpdate:  todov1.TodoEvent_ACTION_TAG_UPDATE,
+	todo.EventTagDelete:  todov1.TodoEvent_ACTION_TAG_DELETE,
+	todo.EventReset:      todov1.TodoEvent_ACTION_RESET,
+}
+
+// eventToProto converts an audit log event passed on by Watch, with the
+// cursor it came with, to its protobuf form.
+func eventToProto(e todo.Event, cursor int64) *todov1.TodoEvent {
+	p := &todov1.TodoEvent{
+		Id:        e.ID,
+		TodoId:    int64(e.TodoID),
+		ActorId:   int64(e.ActorID),
+		Action:    eventActions[e.Action],
+		CreatedAt: timestamppb.New(e.CreatedAt),
+		ListId:    int64Ptr(e.ListID),
+		TagId:     int64Ptr(e.TagID),
+		Name:      e.Name,
+		Cursor:    cursor,
+	}
+	if e.Before != nil {
+		p.Before = toProto(*e.Before)
+	}
+	if e.After != nil {
+		p.After = toProto(*e.After)
+	}
+	return p
+}
+
+// updateRequest builds the changes named by the paths of an update mask
+// from their values in t. A named field that is unset in t is cleared.
+func updateRequest(t *todov1.Todo, paths []string) (todo.UpdateTodoRequest, error) {
+	var req todo.UpdateTodoRequest
+	if len(paths) == 0 {
+		return req, validationError("update_mask must name at least one field")
+	}
+	for _, path := range paths {
+		switch path {
+		case "title":
+			req.Title = &t.Title
+		case "completed":
+			req.Completed = &t.Completed
+		case "due_at":
+			req.DueAt = todo.NullableTime{Set: true, Value: timePtr(t.DueAt)}
+		case "remind_at":
+			req.RemindAt = todo.NullableTime{Set: true, Value: timePtr(t.RemindAt)}
+		case "priority":
+			p := int(t.Priority)
+			req.Priority = &p
+		case "list_id":
+			req.ListID = todo.NullableInt{Set: true, Value: intPtr(t.ListId)}
+		case "parent_id":
+			req.ParentID = todo.NullableInt{Set: true, Value: intPtr(t.ParentId)}
+		case "recurrence":
+			req.Recurrence = &t.Recurrence
+		default:
+			return req, validationError("cannot update %q", path)
+		}
+	}
+	return req, nil
+}
+
+func timestampOf(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func timePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func int64Ptr(n *int) *int64 {
+	if n == nil {
+		return nil
+	}
+	v := int64(*n)
+	return &v
+}
+
+func intPtr(n *int64) *int {
+	if n == nil {
+		return nil
+	}
+	v := int(*n)
+	return &v
+}
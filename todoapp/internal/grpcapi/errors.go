@@ -0,0 +1,48 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"todoapp/internal/todo"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts an error from todo.Service into a gRPC status
+// error. Unexpected errors are logged and reported as Internal with a
+// generic message, as the REST API does.
+func statusError(op string, err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, todo.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, todo.ErrValidation):
+		code = codes.InvalidArgument
+	case errors.Is(err, todo.ErrConflict):
+		code = codes.FailedPrecondition
+	case errors.Is(err, todo.ErrUnauthorized):
+		code = codes.Unauthenticated
+	case errors.Is(err, todo.ErrForbidden):
+		code = codes.PermissionDenied
+	case errors.Is(err, todo.ErrPreconditionFailed), errors.Is(err, todo.ErrAborted):
+		code = codes.Aborted
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	default:
+		log.Printf("Failed to %s: %v", op, err)
+		return status.Error(codes.Internal, "failed to "+op)
+	}
+	return status.Error(code, err.Error())
+}
+
+// validationError returns an error wrapping todo.ErrValidation for a
+// request the service never sees.
+func validationError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", todo.ErrValidation, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,168 @@
+// Package grpcapi serves the todo API over gRPC. The service is defined
+// in todov1/todo.proto and implemented on top of todo.Service, so it
+// follows the same rules as the REST API.
+package grpcapi
+
+import (
+	"context"
+	"net"
+
+	"todoapp/internal/grpcapi/todov1"
+	"todoapp/internal/todo"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Server implements todov1.TodoServiceServer.
+type Server struct {
+	todov1.UnimplementedTodoServiceServer
+
+	service  todo.Service
+	accounts *todo.Accounts
+
+	// stopping is closed when Serve starts shutting down, to end the
+	// WatchTodos streams that would otherwise keep it waiting.
+	stopping chan struct{}
+}
+
+func NewServer(service todo.Service, accounts *todo.Accounts) *Server {
+	return &Server{
+		service:  service,
+		accounts: accounts,
+		stopping: make(chan struct{}),
+	}
+}
+
+// Serve accepts gRPC connections on lis until ctx is done, then stops
+// gracefully. Server reflection is enabled so that tools such as grpcurl
+// can discover the service.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	gs := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.authenticateStream),
+	)
+	todov1.RegisterTodoServiceServer(gs, s)
+	reflection.Register(gs)
+
+	go func() {
+		<-ctx.Done()
+		close(s.stopping)
+		gs.GracefulStop()
+	}()
+	return gs.Serve(lis)
+}
+
+func (s *Server) List(ctx context.Context, req *todov1.ListRequest) (*todov1.ListResponse, error) {
+	opts := todo.ListOptions{
+		Limit:       int(req.GetPageSize()),
+		PageToken:   req.GetPageToken(),
+		Completed:   req.Completed,
+		ListID:      intPtr(req.ListId),
+		Inbox:       req.GetInbox(),
+		ParentID:    intPtr(req.ParentId),
+		TopLevel:    req.GetTopLevel(),
+		Tags:        req.GetTags(),
+		MatchAnyTag: req.GetMatchAnyTag(),
+		Sort:        todo.SortField(req.GetSort()),
+		Descending:  req.GetDescending(),
+	}
+	page, err := s.service.List(ctx, opts)
+	if err != nil {
+		return nil, statusError("list todos", err)
+	}
+	resp := &todov1.ListResponse{NextPageToken: page.NextPageToken}
+	for _, t := range page.Todos {
+		resp.Todos = append(resp.Todos, toProto(t))
+	}
+	return resp, nil
+}
+
+func (s *Server) Create(ctx context.Context, req *todov1.CreateRequest) (*todov1.Todo, error) {
+	t, err := s.service.Create(ctx, todo.CreateTodoRequest{
+		Title:      req.GetTitle(),
+		DueAt:      timePtr(req.GetDueAt()),
+		RemindAt:   timePtr(req.GetRemindAt()),
+		Priority:   int(req.GetPriority()),
+		ListID:     intPtr(req.ListId),
+		ParentID:   intPtr(req.ParentId),
+		Recurrence: req.GetRecurrence(),
+	})
+	if err != nil {
+		return nil, statusError("create todo", err)
+	}
+	return toProto(t), nil
+}
+
+func (s *Server) Get(ctx context.Context, req *todov1.GetRequest) (*todov1.Todo, error) {
+	t, err := s.service.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, statusError("get todo", err)
+	}
+	return toProto(t), nil
+}
+
+func (s *Server) Update(ctx context.Context, req *todov1.UpdateRequest) (*todov1.Todo, error) {
+	if req.GetTodo() == nil {
+		return nil, status.Error(codes.InvalidArgument, "todo is required")
+	}
+	changes, err := updateRequest(req.GetTodo(), req.GetUpdateMask().GetPaths())
+	if err != nil {
+		return nil, statusError("update todo", err)
+	}
+	t, err := s.service.Update(ctx, int(req.GetTodo().GetId()), req.GetTodo().GetVersion(), changes)
+	if err != nil {
+		return nil, statusError("update todo", err)
+	}
+	return toProto(t), nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *todov1.DeleteRequest) (*emptypb.Empty, error) {
+	if err := s.service.Delete(ctx, int(req.GetId()), req.GetVersion()); err != nil {
+		return nil, statusError("delete todo", err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) Toggle(ctx context.Context, req *todov1.ToggleRequest) (*todov1.Todo, error) {
+	t, err := s.service.Toggle(ctx, int(req.GetId()), req.GetVersion(), req.GetCascade())
+	if err != nil {
+		return nil, statusError("toggle todo", err)
+	}
+	return toProto(t), nil
+}
+
+// WatchTodos sends the events the caller can see, oldest first, as
+// their changes are committed. It ends when the client cancels the call
+// or the server shuts down.
+func (s *Server) WatchTodos(req *todov1.WatchTodosRequest, stream grpc.ServerStreamingServer[todov1.TodoEvent]) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := s.service.Watch(ctx, req.GetAfterEventId(), func(e todo.Event, cursor int64) error {
+		return stream.Send(eventToProto(e, cursor))
+	})
+	select {
+	case <-s.stopping:
+		err = nil
+	default:
+	}
+	if err == nil {
+		// Watch only ends without an error when its broker closes.
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return statusError("watch todos", err)
+}
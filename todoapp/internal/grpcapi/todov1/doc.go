@@ -0,0 +1,6 @@
+// Package todov1 holds the protobuf messages and gRPC stubs generated
+// from todo.proto. Regenerate them after changing todo.proto with
+// protoc, protoc-gen-go and protoc-gen-go-grpc on the PATH.
+package todov1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative internal/grpcapi/todov1/todo.proto
@@ -0,0 +1,1293 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: internal/grpcapi/todov1/todo.proto
+
+// The todo API over gRPC. It is served by the same process and the same
+// todo.Service as the REST API, so the rules and errors are the same;
+// see the REST documentation for the meaning of each field.
+//
+// Every call must carry the metadata "authorization: Bearer <token>"
+// with a token from POST /auth/login.
+
+package todov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TodoEvent_Action int32
+
+const (
+	TodoEvent_ACTION_UNSPECIFIED TodoEvent_Action = 0
+	TodoEvent_ACTION_CREATE      TodoEvent_Action = 1
+	TodoEvent_ACTION_UPDATE      TodoEvent_Action = 2
+	TodoEvent_ACTION_TOGGLE      TodoEvent_Action = 3
+	TodoEvent_ACTION_DELETE      TodoEvent_Action = 4
+	TodoEvent_ACTION_RESTORE     TodoEvent_Action = 5
+	TodoEvent_ACTION_MOVE        TodoEvent_Action = 6
+	TodoEvent_ACTION_PURGE       TodoEvent_Action = 7
+	TodoEvent_ACTION_LIST_CREATE TodoEvent_Action = 8
+	TodoEvent_ACTION_LIST_UPDATE TodoEvent_Action = 9
+	TodoEvent_ACTION_LIST_DELETE TodoEvent_Action = 10
+	TodoEvent_ACTION_TAG_CREATE  TodoEvent_Action = 11
+	TodoEvent_ACTION_TAG_UPDATE  TodoEvent_Action = 12
+	TodoEvent_ACTION_TAG_DELETE  TodoEvent_Action = 13
+	// Not a change: sent instead of replaying more than 1000 missed
+	// changes. Reload what is shown; the events after it are of later
+	// changes.
+	TodoEvent_ACTION_RESET TodoEvent_Action = 14
+)
+
+// Enum value maps for TodoEvent_Action.
+var (
+	TodoEvent_Action_name = map[int32]string{
+		0:  "ACTION_UNSPECIFIED",
+		1:  "ACTION_CREATE",
+		2:  "ACTION_UPDATE",
+		3:  "ACTION_TOGGLE",
+		4:  "ACTION_DELETE",
+		5:  "ACTION_RESTORE",
+		6:  "ACTION_MOVE",
+		7:  "ACTION_PURGE",
+		8:  "ACTION_LIST_CREATE",
+		9:  "ACTION_LIST_UPDATE",
+		10: "ACTION_LIST_DELETE",
+		11: "ACTION_TAG_CREATE",
+		12: "ACTION_TAG_UPDATE",
+		13: "ACTION_TAG_DELETE",
+		14: "ACTION_RESET",
+	}
+	TodoEvent_Action_value = map[string]int32{
+		"ACTION_UNSPECIFIED": 0,
+		"ACTION_CREATE":      1,
+		"ACTION_UPDATE":      2,
+		"ACTION_TOGGLE":      3,
+		"ACTION_DELETE":      4,
+		"ACTION_RESTORE":     5,
+		"ACTION_MOVE":        6,
+		"ACTION_PURGE":       7,
+		"ACTION_LIST_CREATE": 8,
+		"ACTION_LIST_UPDATE": 9,
+		"ACTION_LIST_DELETE": 10,
+		"ACTION_TAG_CREATE":  11,
+		"ACTION_TAG_UPDATE":  12,
+		"ACTION_TAG_DELETE":  13,
+		"ACTION_RESET":       14,
+	}
+)
+
+func (x TodoEvent_Action) Enum() *TodoEvent_Action {
+	p := new(TodoEvent_Action)
+	*p = x
+	return p
+}
+
+func (x TodoEvent_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TodoEvent_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_grpcapi_todov1_todo_proto_enumTypes[0].Descriptor()
+}
+
+func (TodoEvent_Action) Type() protoreflect.EnumType {
+	return &file_internal_grpcapi_todov1_todo_proto_enumTypes[0]
+}
+
+func (x TodoEvent_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TodoEvent_Action.Descriptor instead.
+func (TodoEvent_Action) EnumDescriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{11, 0}
+}
+
+type Todo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OwnerId     int64                  `protobuf:"varint,2,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	Title       string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Completed   bool                   `protobuf:"varint,4,opt,name=completed,proto3" json:"completed,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	DueAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	RemindAt    *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=remind_at,json=remindAt,proto3" json:"remind_at,omitempty"`
+	// 0 (none) to 3 (high).
+	Priority int32 `protobuf:"varint,9,opt,name=priority,proto3" json:"priority,omitempty"`
+	Position int64 `protobuf:"varint,10,opt,name=position,proto3" json:"position,omitempty"`
+	// Unset for the inbox.
+	ListId     *int64 `protobuf:"varint,11,opt,name=list_id,json=listId,proto3,oneof" json:"list_id,omitempty"`
+	ParentId   *int64 `protobuf:"varint,12,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Recurrence string `protobuf:"bytes,13,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	Tags       []*Tag `protobuf:"bytes,14,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Set when the todo has subtasks.
+	Progress      *Progress `protobuf:"bytes,15,opt,name=progress,proto3" json:"progress,omitempty"`
+	Version       int64     `protobuf:"varint,16,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Todo) Reset() {
+	*x = Todo{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Todo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Todo) ProtoMessage() {}
+
+func (x *Todo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Todo.ProtoReflect.Descriptor instead.
+func (*Todo) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Todo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Todo) GetOwnerId() int64 {
+	if x != nil {
+		return x.OwnerId
+	}
+	return 0
+}
+
+func (x *Todo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Todo) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *Todo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *Todo) GetRemindAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RemindAt
+	}
+	return nil
+}
+
+func (x *Todo) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Todo) GetPosition() int64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Todo) GetListId() int64 {
+	if x != nil && x.ListId != nil {
+		return *x.ListId
+	}
+	return 0
+}
+
+func (x *Todo) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *Todo) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Todo) GetTags() []*Tag {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Todo) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+func (x *Todo) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type Tag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tag) Reset() {
+	*x = Tag{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tag) ProtoMessage() {}
+
+func (x *Tag) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tag.ProtoReflect.Descriptor instead.
+func (*Tag) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Tag) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Tag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type Progress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Completed     int32                  `protobuf:"varint,1,opt,name=completed,proto3" json:"completed,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Progress) GetCompleted() int32 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *Progress) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Default 50, at most 500.
+	PageSize    int32    `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken   string   `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	Completed   *bool    `protobuf:"varint,3,opt,name=completed,proto3,oneof" json:"completed,omitempty"`
+	ListId      *int64   `protobuf:"varint,4,opt,name=list_id,json=listId,proto3,oneof" json:"list_id,omitempty"`
+	Inbox       bool     `protobuf:"varint,5,opt,name=inbox,proto3" json:"inbox,omitempty"`
+	ParentId    *int64   `protobuf:"varint,6,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	TopLevel    bool     `protobuf:"varint,7,opt,name=top_level,json=topLevel,proto3" json:"top_level,omitempty"`
+	Tags        []string `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+	MatchAnyTag bool     `protobuf:"varint,9,opt,name=match_any_tag,json=matchAnyTag,proto3" json:"match_any_tag,omitempty"`
+	// id, created_at, completed_at, due_at, title, priority or position.
+	Sort          string `protobuf:"bytes,10,opt,name=sort,proto3" json:"sort,omitempty"`
+	Descending    bool   `protobuf:"varint,11,opt,name=descending,proto3" json:"descending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListRequest) GetCompleted() bool {
+	if x != nil && x.Completed != nil {
+		return *x.Completed
+	}
+	return false
+}
+
+func (x *ListRequest) GetListId() int64 {
+	if x != nil && x.ListId != nil {
+		return *x.ListId
+	}
+	return 0
+}
+
+func (x *ListRequest) GetInbox() bool {
+	if x != nil {
+		return x.Inbox
+	}
+	return false
+}
+
+func (x *ListRequest) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *ListRequest) GetTopLevel() bool {
+	if x != nil {
+		return x.TopLevel
+	}
+	return false
+}
+
+func (x *ListRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListRequest) GetMatchAnyTag() bool {
+	if x != nil {
+		return x.MatchAnyTag
+	}
+	return false
+}
+
+func (x *ListRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+type ListResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Todos []*Todo                `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListResponse) GetTodos() []*Todo {
+	if x != nil {
+		return x.Todos
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type CreateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	DueAt         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=due_at,json=dueAt,proto3" json:"due_at,omitempty"`
+	RemindAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=remind_at,json=remindAt,proto3" json:"remind_at,omitempty"`
+	Priority      int32                  `protobuf:"varint,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	ListId        *int64                 `protobuf:"varint,5,opt,name=list_id,json=listId,proto3,oneof" json:"list_id,omitempty"`
+	ParentId      *int64                 `protobuf:"varint,6,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	Recurrence    string                 `protobuf:"bytes,7,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateRequest) Reset() {
+	*x = CreateRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateRequest) ProtoMessage() {}
+
+func (x *CreateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateRequest.ProtoReflect.Descriptor instead.
+func (*CreateRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateRequest) GetDueAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueAt
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetRemindAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RemindAt
+	}
+	return nil
+}
+
+func (x *CreateRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetListId() int64 {
+	if x != nil && x.ListId != nil {
+		return *x.ListId
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetParentId() int64 {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return 0
+}
+
+func (x *CreateRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type UpdateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// todo.id names the todo to update.
+	Todo *Todo `protobuf:"bytes,1,opt,name=todo,proto3" json:"todo,omitempty"`
+	// Paths of the fields to change: title, completed, due_at, remind_at,
+	// priority, list_id, parent_id and recurrence. A named field that is
+	// unset in todo is cleared.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRequest) Reset() {
+	*x = UpdateRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRequest) ProtoMessage() {}
+
+func (x *UpdateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateRequest) GetTodo() *Todo {
+	if x != nil {
+		return x.Todo
+	}
+	return nil
+}
+
+func (x *UpdateRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// If set, the todo must still be at this version.
+	Version       int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type ToggleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// If set, the todo must still be at this version.
+	Version int64 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Also complete the todo's subtasks when completing it.
+	Cascade       bool `protobuf:"varint,3,opt,name=cascade,proto3" json:"cascade,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToggleRequest) Reset() {
+	*x = ToggleRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToggleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToggleRequest) ProtoMessage() {}
+
+func (x *ToggleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToggleRequest.ProtoReflect.Descriptor instead.
+func (*ToggleRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ToggleRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ToggleRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ToggleRequest) GetCascade() bool {
+	if x != nil {
+		return x.Cascade
+	}
+	return false
+}
+
+type WatchTodosRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Replays the changes after this cursor, the cursor of the last
+	// event received, before streaming new ones; zero streams only new
+	// changes.
+	AfterEventId  int64 `protobuf:"varint,1,opt,name=after_event_id,json=afterEventId,proto3" json:"after_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTodosRequest) Reset() {
+	*x = WatchTodosRequest{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTodosRequest) ProtoMessage() {}
+
+func (x *WatchTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTodosRequest.ProtoReflect.Descriptor instead.
+func (*WatchTodosRequest) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchTodosRequest) GetAfterEventId() int64 {
+	if x != nil {
+		return x.AfterEventId
+	}
+	return 0
+}
+
+type TodoEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The audit log event id; zero for a reset. Ids are taken in the
+	// order changes are made, not the order they commit.
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Zero for a list or tag event.
+	TodoId int64 `protobuf:"varint,2,opt,name=todo_id,json=todoId,proto3" json:"todo_id,omitempty"`
+	// Zero for the server itself, which purges the trash.
+	ActorId   int64                  `protobuf:"varint,3,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	Action    TodoEvent_Action       `protobuf:"varint,4,opt,name=action,proto3,enum=todoapp.todo.v1.TodoEvent_Action" json:"action,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// The todo before and after the change; before is unset for a create
+	// and after for a purge.
+	Before *Todo `protobuf:"bytes,6,opt,name=before,proto3" json:"before,omitempty"`
+	After  *Todo `protobuf:"bytes,7,opt,name=after,proto3" json:"after,omitempty"`
+	// The list the todo was in, or the list of a list event.
+	ListId *int64 `protobuf:"varint,8,opt,name=list_id,json=listId,proto3,oneof" json:"list_id,omitempty"`
+	// The tag of a tag event.
+	TagId *int64 `protobuf:"varint,9,opt,name=tag_id,json=tagId,proto3,oneof" json:"tag_id,omitempty"`
+	// The name of the list or tag of a list or tag event.
+	Name string `protobuf:"bytes,10,opt,name=name,proto3" json:"name,omitempty"`
+	// The id up to which every event has been sent; pass the last one
+	// seen as after_event_id to resume. A resumed stream may repeat some
+	// events, which the id tells apart.
+	Cursor        int64 `protobuf:"varint,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TodoEvent) Reset() {
+	*x = TodoEvent{}
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TodoEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TodoEvent) ProtoMessage() {}
+
+func (x *TodoEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpcapi_todov1_todo_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TodoEvent.ProtoReflect.Descriptor instead.
+func (*TodoEvent) Descriptor() ([]byte, []int) {
+	return file_internal_grpcapi_todov1_todo_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TodoEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TodoEvent) GetTodoId() int64 {
+	if x != nil {
+		return x.TodoId
+	}
+	return 0
+}
+
+func (x *TodoEvent) GetActorId() int64 {
+	if x != nil {
+		return x.ActorId
+	}
+	return 0
+}
+
+func (x *TodoEvent) GetAction() TodoEvent_Action {
+	if x != nil {
+		return x.Action
+	}
+	return TodoEvent_ACTION_UNSPECIFIED
+}
+
+func (x *TodoEvent) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TodoEvent) GetBefore() *Todo {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *TodoEvent) GetAfter() *Todo {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *TodoEvent) GetListId() int64 {
+	if x != nil && x.ListId != nil {
+		return *x.ListId
+	}
+	return 0
+}
+
+func (x *TodoEvent) GetTagId() int64 {
+	if x != nil && x.TagId != nil {
+		return *x.TagId
+	}
+	return 0
+}
+
+func (x *TodoEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TodoEvent) GetCursor() int64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+var File_internal_grpcapi_todov1_todo_proto protoreflect.FileDescriptor
+
+const file_internal_grpcapi_todov1_todo_proto_rawDesc = "" +
+	"\n" +
+	"\"internal/grpcapi/todov1/todo.proto\x12\x0ftodoapp.todo.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf8\x04\n" +
+	"\x04Todo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bowner_id\x18\x02 \x01(\x03R\aownerId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1c\n" +
+	"\tcompleted\x18\x04 \x01(\bR\tcompleted\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcompleted_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x121\n" +
+	"\x06due_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x127\n" +
+	"\tremind_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bremindAt\x12\x1a\n" +
+	"\bpriority\x18\t \x01(\x05R\bpriority\x12\x1a\n" +
+	"\bposition\x18\n" +
+	" \x01(\x03R\bposition\x12\x1c\n" +
+	"\alist_id\x18\v \x01(\x03H\x00R\x06listId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\f \x01(\x03H\x01R\bparentId\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\r \x01(\tR\n" +
+	"recurrence\x12(\n" +
+	"\x04tags\x18\x0e \x03(\v2\x14.todoapp.todo.v1.TagR\x04tags\x125\n" +
+	"\bprogress\x18\x0f \x01(\v2\x19.todoapp.todo.v1.ProgressR\bprogress\x12\x18\n" +
+	"\aversion\x18\x10 \x01(\x03R\aversionB\n" +
+	"\n" +
+	"\b_list_idB\f\n" +
+	"\n" +
+	"_parent_id\")\n" +
+	"\x03Tag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\">\n" +
+	"\bProgress\x12\x1c\n" +
+	"\tcompleted\x18\x01 \x01(\x05R\tcompleted\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xf3\x02\n" +
+	"\vListRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12!\n" +
+	"\tcompleted\x18\x03 \x01(\bH\x00R\tcompleted\x88\x01\x01\x12\x1c\n" +
+	"\alist_id\x18\x04 \x01(\x03H\x01R\x06listId\x88\x01\x01\x12\x14\n" +
+	"\x05inbox\x18\x05 \x01(\bR\x05inbox\x12 \n" +
+	"\tparent_id\x18\x06 \x01(\x03H\x02R\bparentId\x88\x01\x01\x12\x1b\n" +
+	"\ttop_level\x18\a \x01(\bR\btopLevel\x12\x12\n" +
+	"\x04tags\x18\b \x03(\tR\x04tags\x12\"\n" +
+	"\rmatch_any_tag\x18\t \x01(\bR\vmatchAnyTag\x12\x12\n" +
+	"\x04sort\x18\n" +
+	" \x01(\tR\x04sort\x12\x1e\n" +
+	"\n" +
+	"descending\x18\v \x01(\bR\n" +
+	"descendingB\f\n" +
+	"\n" +
+	"_completedB\n" +
+	"\n" +
+	"\b_list_idB\f\n" +
+	"\n" +
+	"_parent_id\"c\n" +
+	"\fListResponse\x12+\n" +
+	"\x05todos\x18\x01 \x03(\v2\x15.todoapp.todo.v1.TodoR\x05todos\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xa7\x02\n" +
+	"\rCreateRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x121\n" +
+	"\x06due_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x05dueAt\x127\n" +
+	"\tremind_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bremindAt\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\x05R\bpriority\x12\x1c\n" +
+	"\alist_id\x18\x05 \x01(\x03H\x00R\x06listId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\x06 \x01(\x03H\x01R\bparentId\x88\x01\x01\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\a \x01(\tR\n" +
+	"recurrenceB\n" +
+	"\n" +
+	"\b_list_idB\f\n" +
+	"\n" +
+	"_parent_id\"\x1c\n" +
+	"\n" +
+	"GetRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"w\n" +
+	"\rUpdateRequest\x12)\n" +
+	"\x04todo\x18\x01 \x01(\v2\x15.todoapp.todo.v1.TodoR\x04todo\x12;\n" +
+	"\vupdate_mask\x18\x02 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\"9\n" +
+	"\rDeleteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"S\n" +
+	"\rToggleRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12\x18\n" +
+	"\acascade\x18\x03 \x01(\bR\acascade\"9\n" +
+	"\x11WatchTodosRequest\x12$\n" +
+	"\x0eafter_event_id\x18\x01 \x01(\x03R\fafterEventId\"\xe3\x05\n" +
+	"\tTodoEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\atodo_id\x18\x02 \x01(\x03R\x06todoId\x12\x19\n" +
+	"\bactor_id\x18\x03 \x01(\x03R\aactorId\x129\n" +
+	"\x06action\x18\x04 \x01(\x0e2!.todoapp.todo.v1.TodoEvent.ActionR\x06action\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12-\n" +
+	"\x06before\x18\x06 \x01(\v2\x15.todoapp.todo.v1.TodoR\x06before\x12+\n" +
+	"\x05after\x18\a \x01(\v2\x15.todoapp.todo.v1.TodoR\x05after\x12\x1c\n" +
+	"\alist_id\x18\b \x01(\x03H\x00R\x06listId\x88\x01\x01\x12\x1a\n" +
+	"\x06tag_id\x18\t \x01(\x03H\x01R\x05tagId\x88\x01\x01\x12\x12\n" +
+	"\x04name\x18\n" +
+	" \x01(\tR\x04name\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\x03R\x06cursor\"\xc2\x02\n" +
+	"\x06Action\x12\x16\n" +
+	"\x12ACTION_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rACTION_CREATE\x10\x01\x12\x11\n" +
+	"\rACTION_UPDATE\x10\x02\x12\x11\n" +
+	"\rACTION_TOGGLE\x10\x03\x12\x11\n" +
+	"\rACTION_DELETE\x10\x04\x12\x12\n" +
+	"\x0eACTION_RESTORE\x10\x05\x12\x0f\n" +
+	"\vACTION_MOVE\x10\x06\x12\x10\n" +
+	"\fACTION_PURGE\x10\a\x12\x16\n" +
+	"\x12ACTION_LIST_CREATE\x10\b\x12\x16\n" +
+	"\x12ACTION_LIST_UPDATE\x10\t\x12\x16\n" +
+	"\x12ACTION_LIST_DELETE\x10\n" +
+	"\x12\x15\n" +
+	"\x11ACTION_TAG_CREATE\x10\v\x12\x15\n" +
+	"\x11ACTION_TAG_UPDATE\x10\f\x12\x15\n" +
+	"\x11ACTION_TAG_DELETE\x10\r\x12\x10\n" +
+	"\fACTION_RESET\x10\x0eB\n" +
+	"\n" +
+	"\b_list_idB\t\n" +
+	"\a_tag_id2\xe2\x03\n" +
+	"\vTodoService\x12C\n" +
+	"\x04List\x12\x1c.todoapp.todo.v1.ListRequest\x1a\x1d.todoapp.todo.v1.ListResponse\x12?\n" +
+	"\x06Create\x12\x1e.todoapp.todo.v1.CreateRequest\x1a\x15.todoapp.todo.v1.Todo\x129\n" +
+	"\x03Get\x12\x1b.todoapp.todo.v1.GetRequest\x1a\x15.todoapp.todo.v1.Todo\x12?\n" +
+	"\x06Update\x12\x1e.todoapp.todo.v1.UpdateRequest\x1a\x15.todoapp.todo.v1.Todo\x12@\n" +
+	"\x06Delete\x12\x1e.todoapp.todo.v1.DeleteRequest\x1a\x16.google.protobuf.Empty\x12?\n" +
+	"\x06Toggle\x12\x1e.todoapp.todo.v1.ToggleRequest\x1a\x15.todoapp.todo.v1.Todo\x12N\n" +
+	"\n" +
+	"WatchTodos\x12\".todoapp.todo.v1.WatchTodosRequest\x1a\x1a.todoapp.todo.v1.TodoEvent0\x01B(Z&todoapp/internal/grpcapi/todov1;todov1b\x06proto3"
+
+var (
+	file_internal_grpcapi_todov1_todo_proto_rawDescOnce sync.Once
+	file_internal_grpcapi_todov1_todo_proto_rawDescData []byte
+)
+
+func file_internal_grpcapi_todov1_todo_proto_rawDescGZIP() []byte {
+	file_internal_grpcapi_todov1_todo_proto_rawDescOnce.Do(func() {
+		file_internal_grpcapi_todov1_todo_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_grpcapi_todov1_todo_proto_rawDesc), len(file_internal_grpcapi_todov1_todo_proto_rawDesc)))
+	})
+	return file_internal_grpcapi_todov1_todo_proto_rawDescData
+}
+
+var file_internal_grpcapi_todov1_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_internal_grpcapi_todov1_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_internal_grpcapi_todov1_todo_proto_goTypes = []any{
+	(TodoEvent_Action)(0),         // 0: todoapp.todo.v1.TodoEvent.Action
+	(*Todo)(nil),                  // 1: todoapp.todo.v1.Todo
+	(*Tag)(nil),                   // 2: todoapp.todo.v1.Tag
+	(*Progress)(nil),              // 3: todoapp.todo.v1.Progress
+	(*ListRequest)(nil),           // 4: todoapp.todo.v1.ListRequest
+	(*ListResponse)(nil),          // 5: todoapp.todo.v1.ListResponse
+	(*CreateRequest)(nil),         // 6: todoapp.todo.v1.CreateRequest
+	(*GetRequest)(nil),            // 7: todoapp.todo.v1.GetRequest
+	(*UpdateRequest)(nil),         // 8: todoapp.todo.v1.UpdateRequest
+	(*DeleteRequest)(nil),         // 9: todoapp.todo.v1.DeleteRequest
+	(*ToggleRequest)(nil),         // 10: todoapp.todo.v1.ToggleRequest
+	(*WatchTodosRequest)(nil),     // 11: todoapp.todo.v1.WatchTodosRequest
+	(*TodoEvent)(nil),             // 12: todoapp.todo.v1.TodoEvent
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 14: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),         // 15: google.protobuf.Empty
+}
+var file_internal_grpcapi_todov1_todo_proto_depIdxs = []int32{
+	13, // 0: todoapp.todo.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
+	13, // 1: todoapp.todo.v1.Todo.completed_at:type_name -> google.protobuf.Timestamp
+	13, // 2: todoapp.todo.v1.Todo.due_at:type_name -> google.protobuf.Timestamp
+	13, // 3: todoapp.todo.v1.Todo.remind_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: todoapp.todo.v1.Todo.tags:type_name -> todoapp.todo.v1.Tag
+	3,  // 5: todoapp.todo.v1.Todo.progress:type_name -> todoapp.todo.v1.Progress
+	1,  // 6: todoapp.todo.v1.ListResponse.todos:type_name -> todoapp.todo.v1.Todo
+	13, // 7: todoapp.todo.v1.CreateRequest.due_at:type_name -> google.protobuf.Timestamp
+	13, // 8: todoapp.todo.v1.CreateRequest.remind_at:type_name -> google.protobuf.Timestamp
+	1,  // 9: todoapp.todo.v1.UpdateRequest.todo:type_name -> todoapp.todo.v1.Todo
+	14, // 10: todoapp.todo.v1.UpdateRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 11: todoapp.todo.v1.TodoEvent.action:type_name -> todoapp.todo.v1.TodoEvent.Action
+	13, // 12: todoapp.todo.v1.TodoEvent.created_at:type_name -> google.protobuf.Timestamp
+	1,  // 13: todoapp.todo.v1.TodoEvent.before:type_name -> todoapp.todo.v1.Todo
+	1,  // 14: todoapp.todo.v1.TodoEvent.after:type_name -> todoapp.todo.v1.Todo
+	4,  // 15: todoapp.todo.v1.TodoService.List:input_type -> todoapp.todo.v1.ListRequest
+	6,  // 16: todoapp.todo.v1.TodoService.Create:input_type -> todoapp.todo.v1.CreateRequest
+	7,  // 17: todoapp.todo.v1.TodoService.Get:input_type -> todoapp.todo.v1.GetRequest
+	8,  // 18: todoapp.todo.v1.TodoService.Update:input_type -> todoapp.todo.v1.UpdateRequest
+	9,  // 19: todoapp.todo.v1.TodoService.Delete:input_type -> todoapp.todo.v1.DeleteRequest
+	10, // 20: todoapp.todo.v1.TodoService.Toggle:input_type -> todoapp.todo.v1.ToggleRequest
+	11, // 21: todoapp.todo.v1.TodoService.WatchTodos:input_type -> todoapp.todo.v1.WatchTodosRequest
+	5,  // 22: todoapp.todo.v1.TodoService.List:output_type -> todoapp.todo.v1.ListResponse
+	1,  // 23: todoapp.todo.v1.TodoService.Create:output_type -> todoapp.todo.v1.Todo
+	1,  // 24: todoapp.todo.v1.TodoService.Get:output_type -> todoapp.todo.v1.Todo
+	1,  // 25: todoapp.todo.v1.TodoService.Update:output_type -> todoapp.todo.v1.Todo
+	15, // 26: todoapp.todo.v1.TodoService.Delete:output_type -> google.protobuf.Empty
+	1,  // 27: todoapp.todo.v1.TodoService.Toggle:output_type -> todoapp.todo.v1.Todo
+	12, // 28: todoapp.todo.v1.TodoService.WatchTodos:output_type -> todoapp.todo.v1.TodoEvent
+	22, // [22:29] is the sub-list for method output_type
+	15, // [15:22] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_internal_grpcapi_todov1_todo_proto_init() }
+func file_internal_grpcapi_todov1_todo_proto_init() {
+	if File_internal_grpcapi_todov1_todo_proto != nil {
+		return
+	}
+	file_internal_grpcapi_todov1_todo_proto_msgTypes[0].OneofWrappers = []any{}
+	file_internal_grpcapi_todov1_todo_proto_msgTypes[3].OneofWrappers = []any{}
+	file_internal_grpcapi_todov1_todo_proto_msgTypes[5].OneofWrappers = []any{}
+	file_internal_grpcapi_todov1_todo_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_grpcapi_todov1_todo_proto_rawDesc), len(file_internal_grpcapi_todov1_todo_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_grpcapi_todov1_todo_proto_goTypes,
+		DependencyIndexes: file_internal_grpcapi_todov1_todo_proto_depIdxs,
+		EnumInfos:         file_internal_grpcapi_todov1_todo_proto_enumTypes,
+		MessageInfos:      file_internal_grpcapi_todov1_todo_proto_msgTypes,
+	}.Build()
+	File_internal_grpcapi_todov1_todo_proto = out.File
+	file_internal_grpcapi_todov1_todo_proto_goTypes = nil
+	file_internal_grpcapi_todov1_todo_proto_depIdxs = nil
+}
@@ -0,0 +1,9 @@
+-- IF NOT EXISTS lets databases created by the old CreateTableIfNotExists
+-- adopt migrations without manual intervention.
+CREATE TABLE IF NOT EXISTS todos (
+    id SERIAL PRIMARY KEY,
+    title TEXT NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
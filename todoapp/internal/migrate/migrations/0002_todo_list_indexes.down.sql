@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS todos_completed_id_idx;
+DROP INDEX IF EXISTS todos_title_id_idx;
+DROP INDEX IF EXISTS todos_completed_at_id_idx;
+DROP INDEX IF EXISTS todos_created_at_id_idx;
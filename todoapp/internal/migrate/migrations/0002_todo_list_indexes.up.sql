@@ -0,0 +1,6 @@
+-- Keyset pagination indexes for each GET /todos sort order. The trailing
+-- id column matches the tie-breaker used by the page tokens.
+CREATE INDEX IF NOT EXISTS todos_created_at_id_idx ON todos (created_at, id);
+CREATE INDEX IF NOT EXISTS todos_completed_at_id_idx ON todos (completed_at, id);
+CREATE INDEX IF NOT EXISTS todos_title_id_idx ON todos (title, id);
+CREATE INDEX IF NOT EXISTS todos_completed_id_idx ON todos (completed, id);
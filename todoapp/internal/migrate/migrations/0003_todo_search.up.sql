@@ -0,0 +1,7 @@
+-- Full-text search vector over the searchable todo text. Extend the
+-- expression when more text columns (e.g. a description) are added.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('english', coalesce(title, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS todos_search_vector_idx ON todos USING GIN (search_vector);
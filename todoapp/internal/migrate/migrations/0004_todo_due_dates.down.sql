@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS todos_pending_reminders_idx;
+DROP INDEX IF EXISTS todos_due_at_id_idx;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS reminded_at,
+    DROP COLUMN IF EXISTS remind_at,
+    DROP COLUMN IF EXISTS due_at;
@@ -0,0 +1,10 @@
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS remind_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS reminded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS todos_due_at_id_idx ON todos (due_at, id);
+
+-- Reminders still waiting to fire; kept small by the partial predicate.
+CREATE INDEX IF NOT EXISTS todos_pending_reminders_idx ON todos (remind_at)
+    WHERE reminded_at IS NULL AND NOT completed;
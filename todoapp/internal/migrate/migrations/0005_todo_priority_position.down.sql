@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS todos_priority_id_idx;
+DROP INDEX IF EXISTS todos_position_id_idx;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS priority;
@@ -0,0 +1,14 @@
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0
+        CHECK (priority BETWEEN 0 AND 3),
+    ADD COLUMN IF NOT EXISTS position BIGINT;
+
+-- Existing todos keep their id order, spaced like newly created ones.
+UPDATE todos SET position = s.rn * 1024
+FROM (SELECT id, row_number() OVER (ORDER BY id) AS rn FROM todos) s
+WHERE todos.id = s.id AND todos.position IS NULL;
+
+ALTER TABLE todos ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS todos_position_id_idx ON todos (position, id);
+CREATE INDEX IF NOT EXISTS todos_priority_id_idx ON todos (priority, id);
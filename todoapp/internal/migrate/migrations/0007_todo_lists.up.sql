@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS lists (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Deleting a list moves its todos to the inbox unless the caller deletes
+-- them first.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS list_id INTEGER REFERENCES lists (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS todos_list_id_idx ON todos (list_id, id);
@@ -0,0 +1,5 @@
+-- Deleting a todo deletes its subtasks.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES todos (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS todos_parent_id_idx ON todos (parent_id, id);
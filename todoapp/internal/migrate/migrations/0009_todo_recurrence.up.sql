@@ -0,0 +1,2 @@
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS tags_owner_lower_name_idx;
+DROP INDEX IF EXISTS lists_owner_id_idx;
+DROP INDEX IF EXISTS todos_owner_id_idx;
+
+ALTER TABLE lists DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE tags DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE todos DROP COLUMN IF EXISTS owner_id;
+
+-- Fails if different users had tags with the same name.
+CREATE UNIQUE INDEX IF NOT EXISTS tags_lower_name_idx ON tags (lower(name));
+
+DROP TABLE IF EXISTS users;
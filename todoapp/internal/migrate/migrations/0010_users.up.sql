@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Rows created before accounts existed keep a NULL owner, which no user
+-- can see, until `todomigrate assign-owner EMAIL` hands them to a user.
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE tags
+    ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+ALTER TABLE lists
+    ADD COLUMN IF NOT EXISTS owner_id INTEGER REFERENCES users (id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS todos_owner_id_idx ON todos (owner_id, id);
+CREATE INDEX IF NOT EXISTS lists_owner_id_idx ON lists (owner_id);
+
+-- Tag names are unique per user, ignoring case.
+DROP INDEX IF EXISTS tags_lower_name_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS tags_owner_lower_name_idx ON tags (owner_id, lower(name));
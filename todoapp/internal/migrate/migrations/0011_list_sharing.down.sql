@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS list_invitations;
+DROP TABLE IF EXISTS list_members;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS list_members (
+    list_id INTEGER NOT NULL REFERENCES lists (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS list_members_user_id_idx ON list_members (user_id);
+
+-- Every list's creator is its first owner.
+INSERT INTO list_members (list_id, user_id, role, joined_at)
+SELECT id, owner_id, 'owner', created_at FROM lists WHERE owner_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Invitations are addressed by email so users can be invited before they
+-- register.
+CREATE TABLE IF NOT EXISTS list_invitations (
+    id SERIAL PRIMARY KEY,
+    list_id INTEGER NOT NULL REFERENCES lists (id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    invited_by INTEGER REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (list_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS list_invitations_email_idx ON list_invitations (email);
@@ -0,0 +1,6 @@
+-- Trashed todos would reappear, so delete them for good.
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS todos_deleted_at_idx;
+
+ALTER TABLE todos DROP COLUMN IF EXISTS deleted_at;
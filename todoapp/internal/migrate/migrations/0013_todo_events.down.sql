@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS todo_events;
+
+DROP FUNCTION IF EXISTS todo_events_append_only();
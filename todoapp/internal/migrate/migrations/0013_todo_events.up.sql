@@ -0,0 +1,32 @@
+-- todo_events is the audit log: one row per change to a todo, written in
+-- the same transaction as the change. It has no foreign keys so that the
+-- history outlives purged todos, deleted lists and users. owner_id and
+-- list_id are the todo's at the time of the change and decide who may
+-- read the event.
+CREATE TABLE IF NOT EXISTS todo_events (
+    id BIGSERIAL PRIMARY KEY,
+    todo_id INTEGER NOT NULL,
+    owner_id INTEGER,
+    list_id INTEGER,
+    actor_id INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'toggle', 'delete', 'restore')),
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS todo_events_todo_id_idx ON todo_events (todo_id, id);
+CREATE INDEX IF NOT EXISTS todo_events_list_id_idx ON todo_events (list_id, id);
+CREATE INDEX IF NOT EXISTS todo_events_owner_id_idx ON todo_events (owner_id, id) WHERE list_id IS NULL;
+
+-- The log is append-only.
+CREATE OR REPLACE FUNCTION todo_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'todo_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS todo_events_append_only ON todo_events;
+CREATE TRIGGER todo_events_append_only
+    BEFORE UPDATE OR DELETE ON todo_events
+    FOR EACH ROW EXECUTE FUNCTION todo_events_append_only();
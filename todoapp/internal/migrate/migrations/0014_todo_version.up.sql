@@ -0,0 +1,3 @@
+-- version counts the changes made to a todo; clients send it back in
+-- If-Match so concurrent edits fail instead of overwriting each other.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
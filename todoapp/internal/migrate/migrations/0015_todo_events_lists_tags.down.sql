@@ -0,0 +1,14 @@
+-- The older schema cannot hold the new events, so they are dropped,
+-- which the append-only trigger has to allow for.
+ALTER TABLE todo_events DISABLE TRIGGER todo_events_append_only;
+DELETE FROM todo_events WHERE action NOT IN ('create', 'update', 'toggle', 'delete', 'restore');
+ALTER TABLE todo_events ENABLE TRIGGER todo_events_append_only;
+
+DROP INDEX IF EXISTS todo_events_tag_id_idx;
+
+ALTER TABLE todo_events DROP CONSTRAINT IF EXISTS todo_events_action_check;
+ALTER TABLE todo_events ADD CONSTRAINT todo_events_action_check CHECK (action IN ('create', 'update', 'toggle', 'delete', 'restore'));
+
+ALTER TABLE todo_events DROP COLUMN IF EXISTS name;
+ALTER TABLE todo_events DROP COLUMN IF EXISTS tag_id;
+ALTER TABLE todo_events ALTER COLUMN todo_id SET NOT NULL;
@@ -0,0 +1,14 @@
+-- The audit log also records moves, purges and the changes to lists and
+-- tags. A list or tag event has no todo_id: list_id or tag_id names what
+-- changed, and name is its name.
+ALTER TABLE todo_events ALTER COLUMN todo_id DROP NOT NULL;
+ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS tag_id INTEGER;
+ALTER TABLE todo_events ADD COLUMN IF NOT EXISTS name TEXT;
+
+ALTER TABLE todo_events DROP CONSTRAINT IF EXISTS todo_events_action_check;
+ALTER TABLE todo_events ADD CONSTRAINT todo_events_action_check CHECK (action IN (
+    'create', 'update', 'toggle', 'delete', 'restore', 'move', 'purge',
+    'list_create', 'list_update', 'list_delete', 'tag_create', 'tag_update', 'tag_delete'
+));
+
+CREATE INDEX IF NOT EXISTS todo_events_tag_id_idx ON todo_events (tag_id, id) WHERE tag_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- The older code orders all todos by one sequence of positions.
+UPDATE todos SET position = s.rn * 1024
+FROM (SELECT id, row_number() OVER (ORDER BY position, id) AS rn FROM todos) s
+WHERE todos.id = s.id;
@@ -0,0 +1,9 @@
+-- Positions are kept per list, and per owner for the todos in no list,
+-- but 0005 numbered all todos in one sequence. Each list and inbox is
+-- spread out again in its current order, as Move renumbers it.
+UPDATE todos SET position = s.rn * 1024
+FROM (SELECT id, row_number() OVER (
+          PARTITION BY list_id, CASE WHEN list_id IS NULL THEN owner_id END
+          ORDER BY position, id) AS rn
+      FROM todos) s
+WHERE todos.id = s.id;
@@ -0,0 +1,99 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"todoapp/internal/auth"
+)
+
+// Accounts registers users, logs them in and authenticates their
+// tokens.
+type Accounts struct {
+	repo   Repository
+	tokens *auth.Tokens
+}
+
+// dummyHash is a password hash that Login checks passwords against when
+// there is no user to check them against, so that an unknown email takes
+// as long to reject as a wrong password and cannot be told apart.
+var dummyHash = sync.OnceValue(func() string {
+	hash, err := auth.HashPassword("not the password of any user")
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// NewAccounts creates an Accounts that stores users in repo and signs
+// tokens with tokens.
+func NewAccounts(repo Repository, tokens *auth.Tokens) *Accounts {
+	dummyHash() // hash it now rather than during the first login
+	return &Accounts{repo: repo, tokens: tokens}
+}
+
+// Register creates a user and logs them in.
+func (a *Accounts) Register(ctx context.Context, req CredentialsRequest) (TokenResponse, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if err := validatePassword(req.Password); err != nil {
+		return TokenResponse{}, err
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	u, err := a.repo.CreateUser(ctx, email, hash)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return a.issue(u)
+}
+
+// Login checks a user's credentials and returns a new token. Unknown
+// emails and wrong passwords are both reported as ErrUnauthorized, and
+// take as long to report.
+func (a *Accounts) Login(ctx context.Context, req CredentialsRequest) (TokenResponse, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		auth.CheckPassword(dummyHash(), req.Password)
+		return TokenResponse{}, errBadCredentials
+	}
+	u, err := a.repo.UserByEmail(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		auth.CheckPassword(dummyHash(), req.Password)
+		return TokenResponse{}, errBadCredentials
+	}
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	ok, err := auth.CheckPassword(u.PasswordHash, req.Password)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	if !ok {
+		return TokenResponse{}, errBadCredentials
+	}
+	return a.issue(u)
+}
+
+// Authenticate returns a context carrying the user that token was
+// issued to.
+func (a *Accounts) Authenticate(ctx context.Context, token string) (context.Context, error) {
+	id, err := a.tokens.Verify(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return ContextWithUser(ctx, id), nil
+}
+
+func (a *Accounts) issue(u User) (TokenResponse, error) {
+	token, exp, err := a.tokens.Issue(u.ID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return TokenResponse{Token: token, ExpiresAt: exp, User: u}, nil
+}
@@ -0,0 +1,221 @@
+package todo
+
+import (
+	"encoding/base64"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventAction names the kind of change an Event records.
+type EventAction string
+
+const (
+	EventCreate  EventAction = "create"
+	EventUpdate  EventAction = "update"
+	EventToggle  EventAction = "toggle"
+	EventDelete  EventAction = "delete"
+	EventRestore EventAction = "restore"
+	EventMove    EventAction = "move"
+	// EventPurge is a todo deleted for good from the trash.
+	EventPurge EventAction = "purge"
+
+	EventListCreate EventAction = "list_create"
+	EventListUpdate EventAction = "list_update"
+	EventListDelete EventAction = "list_delete"
+	EventTagCreate  EventAction = "tag_create"
+	EventTagUpdate  EventAction = "tag_update"
+	EventTagDelete  EventAction = "tag_delete"
+)
+
+// EventReset is never recorded: Watch sends it instead of replaying more
+// than maxReplay events, to tell the watcher to reload what it shows.
+// The events after it are those of later changes.
+const EventReset EventAction = "reset"
+
+var eventActions = []EventAction{
+	EventCreate, EventUpdate, EventToggle, EventDelete, EventRestore, EventMove, EventPurge,
+	EventListCreate, EventListUpdate, EventListDelete, EventTagCreate, EventTagUpdate, EventTagDelete,
+}
+
+// Event is an entry in the audit log. The repository appends one for
+// every change to a todo, list or tag, in the same transaction as the
+// change; subtasks completed by a cascade or trashed along with their
+// parent get their own events, and so does every todo a deleted list
+// moves to the inbox.
+type Event struct {
+	ID int64 `json:"id" example:"1"`
+	// TodoID is the todo that changed, or 0 for a list or tag event.
+	TodoID int `json:"todo_id,omitempty" example:"1"`
+	// ListID is the list the todo was in when it changed, or nil for the
+	// inbox. For a list event it is the list that changed.
+	ListID *int `json:"list_id,omitempty" example:"1"`
+	// TagID is the tag that changed, for a tag event.
+	TagID *int `json:"tag_id,omitempty"`
+	// ActorID is the user who made the change, or 0 for the server
+	// itself, which purges the trash.
+	ActorID int         `json:"actor_id" example:"1"`
+	Action  EventAction `json:"action" example:"update"`
+	// Name is the name of the list or tag of a list or tag event, as the
+	// change left it or, for a delete, as it was.
+	Name string `json:"name,omitempty"`
+	// Before is the todo as it was before the change; nil for create.
+	Before *Todo `json:"before,omitempty"`
+	// After is the todo as the change left it; nil for purge.
+	After     *Todo     `json:"after,omitempty"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+
+	// ownerID is the owner of the todo or tag when it changed, or of the
+	// list. Together with ListID it decides who can read the event, the
+	// same way as for the todo itself.
+	ownerID int
+}
+
+// newEvent returns an unsaved event by actor for a change of a todo from
+// before to after, either of which may be nil.
+func newEvent(actor int, action EventAction, before, after *Todo) Event {
+	e := Event{ActorID: actor, Action: action, Before: snapshot(before), After: snapshot(after)}
+	t := after
+	if t == nil {
+		t = before
+	}
+	e.TodoID = t.ID
+	e.ownerID = t.OwnerID
+	e.ListID = copyInt(t.ListID)
+	return e
+}
+
+// newListEvent returns an unsaved event by actor for a change to list l.
+func newListEvent(actor int, action EventAction, l TodoList) Event {
+	return Event{ActorID: actor, Action: action, ListID: &l.ID, Name: l.Name, ownerID: l.OwnerID}
+}
+
+// newTagEvent returns an unsaved event by actor for a change to tag t.
+func newTagEvent(actor int, action EventAction, t Tag) Event {
+	return Event{ActorID: actor, Action: action, TagID: &t.ID, Name: t.Name, ownerID: t.OwnerID}
+}
+
+// snapshot returns a copy of t to store in an event. Progress is left
+// out: it describes the subtasks, which have events of their own.
+func snapshot(t *Todo) *Todo {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.Progress = nil
+	return &c
+}
+
+// EventFilter selects which events ListEvents returns. Events are
+// returned newest first; the zero value returns the latest
+// DefaultPageSize events the user can see.
+type EventFilter struct {
+	// Limit is the maximum number of events to return. Zero means
+	// DefaultPageSize.
+	Limit int
+	// PageToken continues a previous listing. It must come from
+	// EventPage.NextPageToken.
+	PageToken string
+
+	TodoID  *int
+	ListID  *int
+	ActorID *int
+	// Action, when set, keeps only events of that kind.
+	Action EventAction
+	// After and Before bound the time of the change, exclusively.
+	After  *time.Time
+	Before *time.Time
+}
+
+// EventPage is one page of ListEvents results.
+type EventPage struct {
+	Events []Event `json:"events"`
+	// NextPageToken is empty on the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// Validate checks the filter and reports problems as ErrValidation.
+func (f EventFilter) Validate() error {
+	if f.Limit < 0 || f.Limit > MaxPageSize {
+		return validationError("limit must be between 1 and %d", MaxPageSize)
+	}
+	if f.Action != "" && !slices.Contains(eventActions, f.Action) {
+		return validationError("action must be one of %s", joinActions(eventActions))
+	}
+	_, err := f.cursor()
+	return err
+}
+
+// joinActions lists actions for an error message.
+func joinActions(actions []EventAction) string {
+	names := make([]string, len(actions))
+	for i, a := range actions {
+		names[i] = string(a)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f EventFilter) limit() int {
+	if f.Limit == 0 {
+		return DefaultPageSize
+	}
+	return f.Limit
+}
+
+// cursor decodes PageToken into the id of the last event on the previous
+// page. It returns 0 if there is no token.
+func (f EventFilter) cursor() (int64, error) {
+	if f.PageToken == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(f.PageToken)
+	if err != nil {
+		return 0, validationError("invalid page token")
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id < 1 {
+		return 0, validationError("invalid page token")
+	}
+	return id, nil
+}
+
+// matches reports whether e passes the filter, apart from the page
+// token and limit.
+func (f EventFilter) matches(e Event) bool {
+	if f.TodoID != nil && e.TodoID != *f.TodoID {
+		return false
+	}
+	if f.ListID != nil && (e.ListID == nil || *e.ListID != *f.ListID) {
+		return false
+	}
+	if f.ActorID != nil && e.ActorID != *f.ActorID {
+		return false
+	}
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.After != nil && !e.CreatedAt.After(*f.After) {
+		return false
+	}
+	if f.Before != nil && !e.CreatedAt.Before(*f.Before) {
+		return false
+	}
+	return true
+}
+
+// newEventPage builds an EventPage from up to limit()+1 events, newest
+// first; the extra event, if present, only signals that another page
+// exists.
+func newEventPage(events []Event, f EventFilter) EventPage {
+	if events == nil {
+		events = []Event{}
+	}
+	if len(events) <= f.limit() {
+		return EventPage{Events: events}
+	}
+	events = events[:f.limit()]
+	last := events[len(events)-1].ID
+	token := base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(last, 10)))
+	return EventPage{Events: events, NextPageToken: token}
+}
@@ -0,0 +1,106 @@
+package todo
+
+import "errors"
+
+// MaxBatchSize is the largest number of operations a batch may hold.
+const MaxBatchSize = 500
+
+// BatchMode selects what Batch does when an operation fails.
+type BatchMode string
+
+const (
+	// BatchAllOrNothing applies every operation or, if any of them
+	// fails, none of them. It is the default.
+	BatchAllOrNothing BatchMode = "all_or_nothing"
+	// BatchBestEffort applies every operation that succeeds and leaves
+	// out the ones that fail.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchOp names the kind of a batch operation.
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchToggle BatchOp = "toggle"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchRequest represents the request body for applying several
+// operations in one transaction.
+type BatchRequest struct {
+	// Mode is all_or_nothing (the default) or best_effort.
+	Mode       BatchMode        `json:"mode,omitempty" example:"all_or_nothing"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is one operation of a batch. Each behaves like the
+// endpoint of the same name.
+type BatchOperation struct {
+	// Op is create, update, toggle or delete.
+	Op BatchOp `json:"op" example:"toggle"`
+	// ID is the todo to update, toggle or delete.
+	ID int `json:"id,omitempty" example:"1"`
+	// Version, if set, makes an update, toggle or delete fail unless the
+	// todo is still at that version, like If-Match.
+	Version int64 `json:"version,omitempty" example:"3"`
+	// Cascade also completes the subtasks of a todo that toggle
+	// completes.
+	Cascade bool `json:"cascade,omitempty" example:"false"`
+	// Create is the todo to create.
+	Create *CreateTodoRequest `json:"create,omitempty"`
+	// Update holds the changes to make.
+	Update *UpdateTodoRequest `json:"update,omitempty"`
+}
+
+// BatchResult is the outcome of one operation of a batch.
+type BatchResult struct {
+	Op BatchOp
+	// ID is the todo the operation applied to; for create, the new todo.
+	ID int
+	// Todo is the todo as the operation left it, unless it failed or
+	// deleted the todo.
+	Todo *Todo
+	Err  error
+}
+
+func (req BatchRequest) validate() error {
+	switch req.Mode {
+	case "", BatchAllOrNothing, BatchBestEffort:
+	default:
+		return validationError("mode must be all_or_nothing or best_effort")
+	}
+	if len(req.Operations) == 0 {
+		return validationError("operations must not be empty")
+	}
+	if len(req.Operations) > MaxBatchSize {
+		return validationError("a batch may hold at most %d operations", MaxBatchSize)
+	}
+	return nil
+}
+
+// errBatchFailed aborts the transaction of an all-or-nothing batch once
+// an operation fails; the operation's own error is in its result.
+var errBatchFailed = errors.New("batch operation failed")
+
+// BatchResponse is the response body of a batch.
+type BatchResponse struct {
+	// Committed reports whether the batch's changes were saved: always
+	// in best-effort mode, and in all-or-nothing mode only if every
+	// operation succeeded.
+	Committed bool                   `json:"committed" example:"true"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+// BatchOperationResult is the outcome of one operation of a batch, in
+// the order of the request.
+type BatchOperationResult struct {
+	Op BatchOp `json:"op" example:"toggle"`
+	ID int     `json:"id,omitempty" example:"1"`
+	// Status is the HTTP status the operation would have had on its
+	// own; 424 for an operation undone because another one failed.
+	Status int    `json:"status" example:"200"`
+	Todo   *Todo  `json:"todo,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
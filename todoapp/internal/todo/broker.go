@@ -0,0 +1,157 @@
+package todo
+
+import (
+	"context"
+	"sync"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind
+// before the broker drops it.
+const subscriberBuffer = 256
+
+// recentEvents is how many published event ids the broker remembers to
+// drop the events published again.
+const recentEvents = 4096
+
+// Broker passes the events of committed changes from the Service call
+// that made them, or the EventListener that heard of them, to the Watch
+// calls of the same process.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	closed bool
+
+	// recent holds the ids of the latest published events, oldest
+	// first, and seen holds the same ids.
+	recent []int64
+	seen   map[int64]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		subs: make(map[chan Event]struct{}),
+		seen: make(map[int64]struct{}),
+	}
+}
+
+// Publish sends events to every subscriber without blocking, apart from
+// the events it already sent. A subscriber that has fallen too far
+// behind is dropped instead: its channel is closed, and Watch catches up
+// from the audit log.
+func (b *Broker) Publish(events ...Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	events = b.dedup(events)
+	for ch := range b.subs {
+		for _, e := range events {
+			select {
+			case ch <- e:
+				continue
+			default:
+			}
+			delete(b.subs, ch)
+			close(ch)
+			break
+		}
+	}
+}
+
+// dedup returns the events not published before and remembers them.
+// b.mu must be held.
+func (b *Broker) dedup(events []Event) []Event {
+	fresh := events[:0:0]
+	for _, e := range events {
+		if _, ok := b.seen[e.ID]; ok {
+			continue
+		}
+		fresh = append(fresh, e)
+		b.seen[e.ID] = struct{}{}
+		b.recent = append(b.recent, e.ID)
+		if len(b.recent) > recentEvents {
+			delete(b.seen, b.recent[0])
+			b.recent = b.recent[1:]
+		}
+	}
+	return fresh
+}
+
+// Close ends every subscription. Later subscriptions end immediately.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subs {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
+
+// subscribe returns a channel of the events published from now on, which
+// is closed when the subscriber is dropped or the broker closes, and a
+// function that ends the subscription.
+func (b *Broker) subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, subscriberBuffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// recorder collects the events a Service call or purge records, so that
+// they can be published once they are committed.
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+type recorderKey struct{}
+
+// recorderFrom returns the recorder of the Service call ctx belongs to,
+// or nil outside of one.
+func recorderFrom(ctx context.Context) *recorder {
+	rec, _ := ctx.Value(recorderKey{}).(*recorder)
+	return rec
+}
+
+// add collects a recorded event. It does nothing on a nil recorder.
+func (rec *recorder) add(e Event) {
+	if rec == nil {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.events = append(rec.events, e)
+}
+
+// mark returns a function that forgets the events collected since, for
+// a transaction or savepoint that rolled back.
+func (rec *recorder) mark() (rollback func()) {
+	if rec == nil {
+		return func() {}
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	n := len(rec.events)
+	return func() {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.events = rec.events[:n]
+	}
+}
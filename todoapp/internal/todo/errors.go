@@ -0,0 +1,40 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors returned by Service and Repository implementations.
+// Callers should test for them with errors.Is; the returned errors may
+// wrap them with more detail.
+var (
+	ErrNotFound     = errors.New("todo not found")
+	ErrValidation   = errors.New("validation failed")
+	ErrConflict     = errors.New("conflict")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	// ErrPreconditionFailed reports a change conditional on a version of
+	// a todo that is no longer current.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrAborted reports a batch operation that was undone or skipped
+	// because another operation of the batch failed.
+	ErrAborted = errors.New("aborted")
+	// ErrTooLarge reports a request body longer than its handler reads.
+	ErrTooLarge = errors.New("request too large")
+)
+
+// errBadCredentials is returned for a failed login, whatever the reason.
+var errBadCredentials = fmt.Errorf("%w: invalid email or password", ErrUnauthorized)
+
+// staleVersion returns an error wrapping ErrPreconditionFailed for a
+// change of todo id that expected version.
+func staleVersion(id int, version int64) error {
+	return fmt.Errorf("%w: todo %d is no longer at version %d", ErrPreconditionFailed, id, version)
+}
+
+// validationError returns an error wrapping ErrValidation with a message
+// suitable for showing to API clients.
+func validationError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrValidation, fmt.Sprintf(format, args...))
+}
@@ -1,7 +1,9 @@
 package todo
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,37 +16,79 @@ import (
 
 // Handler handles HTTP requests.
 type Handler struct {
-	service Service
+	service  Service
+	accounts *Accounts
 }
 
 // NewHandler creates a new Handler.
-func NewHandler(service Service) *Handler {
-	return &Handler{service: service}
+func NewHandler(service Service, accounts *Accounts) *Handler {
+	return &Handler{service: service, accounts: accounts}
 }
 
-// RegisterRoutes registers the routes for todo endpoints.
+// RegisterRoutes registers the routes for todo endpoints. Everything but
+// registration and login requires a bearer token.
 func (h *Handler) RegisterRoutes(r *mux.Router) {
-	r.HandleFunc("/todos", h.todosHandler).Methods("GET", "POST")
-	r.HandleFunc("/todos/{id}", h.todoItemHandler).Methods("GET", "PUT", "DELETE")
-	r.HandleFunc("/todos/{id}/toggle", h.toggleHandler).Methods("POST")
-}
-
-// CreateTodoRequest represents the request body for creating a todo.
-type CreateTodoRequest struct {
-	Title string `json:"title" example:"Buy groceries"`
-}
+	r.HandleFunc("/auth/register", h.registerHandler).Methods("POST")
+	r.HandleFunc("/auth/login", h.loginHandler).Methods("POST")
 
-// UpdateTodoRequest represents the request body for updating a todo.
-type UpdateTodoRequest struct {
-	Title     *string `json:"title,omitempty" example:"Updated title"`
-	Completed *bool   `json:"completed,omitempty" example:"true"`
+	api := r.NewRoute().Subrouter()
+	api.Use(h.authenticate)
+	api.HandleFunc("/todos", h.todosHandler).Methods("GET", "POST")
+	api.HandleFunc("/todos/search", h.searchHandler).Methods("GET")
+	api.HandleFunc("/todos/overdue", h.overdueHandler).Methods("GET")
+	api.HandleFunc("/todos/due-today", h.dueTodayHandler).Methods("GET")
+	api.HandleFunc("/todos/tree", h.treeHandler).Methods("GET")
+	api.HandleFunc("/todos/trash", h.trashHandler).Methods("GET")
+	api.HandleFunc("/todos/batch", h.batchHandler).Methods("POST")
+	api.HandleFunc("/todos/export", h.exportHandler).Methods("GET")
+	api.HandleFunc("/todos/import", h.importHandler).Methods("POST")
+	api.HandleFunc("/todos/events", h.eventsHandler).Methods("GET")
+	api.HandleFunc("/todos/{id}", h.todoItemHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/todos/{id}", h.patchHandler).Methods("PATCH")
+	api.HandleFunc("/todos/{id}/toggle", h.toggleHandler).Methods("POST")
+	api.HandleFunc("/todos/{id}/move", h.moveHandler).Methods("POST")
+	api.HandleFunc("/todos/{id}/skip", h.skipHandler).Methods("POST")
+	api.HandleFunc("/todos/{id}/restore", h.restoreHandler).Methods("POST")
+	api.HandleFunc("/todos/{id}/children", h.childrenHandler).Methods("GET")
+	api.HandleFunc("/todos/{id}/history", h.historyHandler).Methods("GET")
+	api.HandleFunc("/todos/{id}/tags", h.attachTagHandler).Methods("POST")
+	api.HandleFunc("/todos/{id}/tags/{tagID}", h.detachTagHandler).Methods("DELETE")
+	api.HandleFunc("/tags", h.tagsHandler).Methods("GET", "POST")
+	api.HandleFunc("/tags/{id}", h.tagItemHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/lists", h.listsHandler).Methods("GET", "POST")
+	api.HandleFunc("/lists/{id}", h.listItemHandler).Methods("GET", "PUT", "DELETE")
+	api.HandleFunc("/lists/{id}/members", h.membersHandler).Methods("GET")
+	api.HandleFunc("/lists/{id}/members/{userID}", h.memberItemHandler).Methods("PUT", "DELETE")
+	api.HandleFunc("/lists/{id}/invitations", h.listInvitationsHandler).Methods("GET", "POST")
+	api.HandleFunc("/invitations", h.invitationsHandler).Methods("GET")
+	api.HandleFunc("/invitations/{id}", h.invitationItemHandler).Methods("DELETE")
+	api.HandleFunc("/invitations/{id}/accept", h.acceptInvitationHandler).Methods("POST")
+	api.HandleFunc("/audit", h.auditHandler).Methods("GET")
 }
 
 // todosHandler handles GET /todos and POST /todos.
 // @Summary List all todos or create a new todo
 // @Tags todos
+// @Security BearerAuth
 // @Produce json
-// @Success 200 {array} Todo "List of todos"
+// @Param limit query int false "Maximum number of todos to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param completed query bool false "Only todos with this completion state"
+// @Param created_after query string false "Only todos created after this RFC 3339 time"
+// @Param created_before query string false "Only todos created before this RFC 3339 time"
+// @Param completed_after query string false "Only todos completed after this RFC 3339 time"
+// @Param completed_before query string false "Only todos completed before this RFC 3339 time"
+// @Param due_after query string false "Only todos due after this RFC 3339 time"
+// @Param due_before query string false "Only todos due before this RFC 3339 time"
+// @Param list_id query string false "Only todos in this list, or inbox for todos in no list"
+// @Param parent_id query string false "Only subtasks of this todo, or none for top-level todos"
+// @Param tags query string false "Comma-separated tag names to filter by"
+// @Param tag_match query string false "Tag filter semantics: all (default) or any"
+// @Param sort query string false "Sort field: id, created_at, completed_at, due_at, title, priority or position"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {object} Page "Page of todos"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /todos [get]
 // @Param todo body CreateTodoRequest false "Todo to create"
@@ -54,20 +98,17 @@ type UpdateTodoRequest struct {
 func (h *Handler) todosHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		todos, err := h.service.List()
+		opts, err := parseListOptions(r)
 		if err != nil {
-			log.Printf("Failed to list todos: %v", err)
-			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
-				"error": "failed to list todos",
-				"details": err.Error(),
-			})
+			writeError(w, "list todos", err)
 			return
 		}
-		// Return empty array instead of null when no todos exist
-		if todos == nil {
-			todos = []Todo{}
+		page, err := h.service.List(r.Context(), opts)
+		if err != nil {
+			writeError(w, "list todos", err)
+			return
 		}
-		writeJSON(w, http.StatusOK, todos)
+		writeJSON(w, http.StatusOK, page)
 
 	case http.MethodPost:
 		var req CreateTodoRequest
@@ -75,41 +116,41 @@ func (h *Handler) todosHandler(w http.ResponseWriter, r *http.Request) {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
 			return
 		}
-		if strings.TrimSpace(req.Title) == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title is required"})
-			return
-		}
-		todo, err := h.service.Create(req.Title)
+		todo, err := h.service.Create(r.Context(), req)
 		if err != nil {
-			log.Printf("Failed to create todo: %v", err)
-			writeJSON(w, http.StatusInternalServerError, map[string]interface{}{
-				"error": "failed to create todo",
-				"details": err.Error(),
-			})
+			writeError(w, "create todo", err)
 			return
 		}
 		log.Printf("Successfully created todo with ID: %d", todo.ID)
-		writeJSON(w, http.StatusCreated, todo)
+		writeTodo(w, http.StatusCreated, todo)
 
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
-
 // todoItemHandler handles GET /todos/{id}, PUT /todos/{id}, and DELETE /todos/{id}.
 // @Summary Get, update, or delete a todo
+// @Description Deleting a todo moves it and its subtasks to the trash. GET and PUT return the todo's version as its ETag; with If-Match, PUT and DELETE fail with 412 if the todo has changed since.
 // @Tags todos
+// @Security BearerAuth
 // @Produce json
 // @Param id path int true "Todo ID"
 // @Success 200 {object} Todo "Todo details"
+// @Header 200 {string} ETag "Version of the todo"
 // @Failure 400 {object} map[string]string "Invalid ID format"
 // @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
 // @Router /todos/{id} [get]
+// @Param If-Match header string false "ETag of the version the change applies to"
 // @Param todo body UpdateTodoRequest false "Todo data to update"
 // @Success 200 {object} Todo "Updated todo"
+// @Failure 409 {object} map[string]string "Conflicting change"
+// @Failure 412 {object} map[string]string "The todo has changed since the If-Match version"
 // @Router /todos/{id} [put]
 // @Success 200 {object} map[string]string "Success message"
+// @Failure 403 {object} map[string]string "Caller may not change the todo"
 // @Router /todos/{id} [delete]
 func (h *Handler) todoItemHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -121,57 +162,44 @@ func (h *Handler) todoItemHandler(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		t, err := h.service.Get(id)
+		t, err := h.service.Get(r.Context(), id)
 		if err != nil {
-			writeJSON(w, http.StatusNotFound, map[string]string{
-				"error":   "todo not found",
-				"details": err.Error(),
-			})
+			writeError(w, "get todo", err)
 			return
 		}
-		writeJSON(w, http.StatusOK, t)
+		writeTodo(w, http.StatusOK, t)
 
 	case http.MethodPut:
+		version, err := parseIfMatch(r)
+		if err != nil {
+			writeError(w, "update todo", err)
+			return
+		}
 		var req UpdateTodoRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
 			return
 		}
 
-		t, err := h.service.Get(id)
+		updated, err := h.service.Update(r.Context(), id, version, req)
 		if err != nil {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "todo not found"})
+			writeError(w, "update todo", err)
 			return
 		}
 
-		if req.Title != nil {
-			t.Title = *req.Title
-		}
+		writeTodo(w, http.StatusOK, updated)
 
-		if req.Completed != nil {
-			t.Completed = *req.Completed
-			if t.Completed && t.CompletedAt == nil {
-				timeNow := time.Now()
-				t.CompletedAt = &timeNow
-			} else if !t.Completed {
-				t.CompletedAt = nil
-			}
-		}
-
-		updated, err := h.service.Update(id, t.Title, t.Completed)
+	case http.MethodDelete:
+		version, err := parseIfMatch(r)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update todo"})
+			writeError(w, "delete todo", err)
 			return
 		}
-
-		writeJSON(w, http.StatusOK, updated)
-
-	case http.MethodDelete:
-		if err := h.service.Delete(id); err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete todo"})
+		if err := h.service.Delete(r.Context(), id, version); err != nil {
+			writeError(w, "delete todo", err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]string{"message": "todo deleted successfully"})
+		writeJSON(w, http.StatusOK, map[string]string{"message": "todo moved to trash"})
 
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -179,15 +207,21 @@ func (h *Handler) todoItemHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-
 // toggleHandler handles POST /todos/{id}/toggle.
 // @Summary Toggle todo completion status
 // @Tags todos
+// @Security BearerAuth
 // @Produce json
 // @Param id path int true "Todo ID"
+// @Param cascade query bool false "When completing, also complete all subtasks"
+// @Param If-Match header string false "ETag of the version the change applies to"
 // @Success 200 {object} Todo "Updated todo"
+// @Header 200 {string} ETag "Version of the todo"
 // @Failure 400 {object} map[string]string "Invalid ID format"
 // @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 412 {object} map[string]string "The todo has changed since the If-Match version"
+// @Failure 500 {object} map[string]string "Internal server error"
 // @Router /todos/{id}/toggle [post]
 func (h *Handler) toggleHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -197,18 +231,483 @@ func (h *Handler) toggleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	todo, err := h.service.Toggle(id)
-if err != nil {
-    if err.Error() == "todo not found" {
-        writeJSON(w, http.StatusNotFound, map[string]string{"error": "todo not found"})
-        return
-    }
-    writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
-    return
+	var cascade bool
+	if v := r.URL.Query().Get("cascade"); v != "" {
+		cascade, err = strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, "toggle todo", validationError("cascade must be true or false"))
+			return
+		}
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, "toggle todo", err)
+		return
+	}
+
+	todo, err := h.service.Toggle(r.Context(), id, version, cascade)
+	if err != nil {
+		writeError(w, "toggle todo", err)
+		return
+	}
+	writeTodo(w, http.StatusOK, todo)
+}
+
+// moveHandler handles POST /todos/{id}/move.
+// @Summary Move a todo before or after another todo
+// @Description Changes the todo's position, used when listing with sort=position. Positions are kept per list, and per owner for todos in no list, so the anchor must be in the same list or inbox as the todo.
+// @Tags todos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param move body MoveRequest true "Anchor todo: exactly one of before or after"
+// @Success 200 {object} Todo "Moved todo"
+// @Header 200 {string} ETag "Version of the todo"
+// @Failure 400 {object} map[string]string "Invalid request, or anchor in another list"
+// @Failure 404 {object} map[string]string "Todo or anchor not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/move [post]
+func (h *Handler) moveHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+
+	var req MoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+
+	todo, err := h.service.Move(r.Context(), id, req)
+	if err != nil {
+		writeError(w, "move todo", err)
+		return
+	}
+	writeTodo(w, http.StatusOK, todo)
+}
+
+// skipHandler handles POST /todos/{id}/skip.
+// @Summary Skip the current occurrence of a recurring todo
+// @Description Moves the due date (and reminder) to the next occurrence without completing the todo.
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} Todo "Rescheduled todo"
+// @Header 200 {string} ETag "Version of the todo"
+// @Failure 400 {object} map[string]string "Todo does not recur or is completed"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/skip [post]
+func (h *Handler) skipHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+
+	todo, err := h.service.Skip(r.Context(), id)
+	if err != nil {
+		writeError(w, "skip occurrence", err)
+		return
+	}
+	writeTodo(w, http.StatusOK, todo)
+}
+
+// searchHandler handles GET /todos/search.
+// @Summary Full-text search over todos
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param q query string true "Search query; supports \"quoted phrases\", or, and -excluded words"
+// @Param limit query int false "Maximum number of results (default 20, max 100)"
+// @Success 200 {array} SearchResult "Matching todos, most relevant first"
+// @Failure 400 {object} map[string]string "Invalid query"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/search [get]
+func (h *Handler) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, "search todos", validationError("limit must be a positive integer"))
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.service.Search(r.Context(), q.Get("q"), limit)
+	if err != nil {
+		writeError(w, "search todos", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// trashHandler handles GET /todos/trash.
+// @Summary List todos in the trash
+// @Description Trashed todos are purged permanently once the retention period has passed.
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of todos to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param sort query string false "Sort field (default id)"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {object} Page "Page of trashed todos"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/trash [get]
+func (h *Handler) trashHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, "list trash", err)
+		return
+	}
+	page, err := h.service.Trash(r.Context(), opts)
+	if err != nil {
+		writeError(w, "list trash", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// restoreHandler handles POST /todos/{id}/restore.
+// @Summary Restore a todo from the trash
+// @Description Subtasks deleted along with the todo are restored too. A subtask cannot be restored while its parent is in the trash.
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Success 200 {object} Todo "Restored todo"
+// @Failure 400 {object} map[string]string "Invalid ID format"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 403 {object} map[string]string "Caller may not change the todo"
+// @Failure 404 {object} map[string]string "Todo not in the trash"
+// @Failure 409 {object} map[string]string "Parent todo is in the trash"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/restore [post]
+func (h *Handler) restoreHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	t, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, "restore todo", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// overdueHandler handles GET /todos/overdue.
+// @Summary List open todos that are past their due date
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of todos to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param sort query string false "Sort field (default due_at)"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {object} Page "Page of overdue todos"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/overdue [get]
+func (h *Handler) overdueHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, "list overdue todos", err)
+		return
+	}
+	page, err := h.service.Overdue(r.Context(), opts)
+	if err != nil {
+		writeError(w, "list overdue todos", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// dueTodayHandler handles GET /todos/due-today.
+// @Summary List todos due today
+// @Description "Today" is the current calendar day in the tz time zone.
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param tz query string false "IANA time zone name, e.g. Europe/Berlin (default UTC)"
+// @Param completed query bool false "Only todos with this completion state"
+// @Param limit query int false "Maximum number of todos to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param sort query string false "Sort field (default due_at)"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {object} Page "Page of todos due today"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/due-today [get]
+func (h *Handler) dueTodayHandler(w http.ResponseWriter, r *http.Request) {
+	loc := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			writeError(w, "list todos due today", validationError("unknown time zone %q", tz))
+			return
+		}
+		loc = l
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, "list todos due today", err)
+		return
+	}
+	page, err := h.service.DueToday(r.Context(), loc, opts)
+	if err != nil {
+		writeError(w, "list todos due today", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// childrenHandler handles GET /todos/{id}/children.
+// @Summary List the direct subtasks of a todo
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param limit query int false "Maximum number of todos to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param completed query bool false "Only subtasks with this completion state"
+// @Param sort query string false "Sort field (default id)"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {object} Page "Page of subtasks"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/children [get]
+func (h *Handler) childrenHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, "list subtasks", err)
+		return
+	}
+	page, err := h.service.Children(r.Context(), id, opts)
+	if err != nil {
+		writeError(w, "list subtasks", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// treeHandler handles GET /todos/tree.
+// @Summary Get all todos as a tree of subtasks
+// @Description Top-level todos and each todo's subtasks are in position order.
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} TodoNode "Top-level todos with nested subtasks"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/tree [get]
+func (h *Handler) treeHandler(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.Tree(r.Context())
+	if err != nil {
+		writeError(w, "get todo tree", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tree)
+}
+
+// parseListOptions reads the GET /todos query parameters.
+func parseListOptions(r *http.Request) (ListOptions, error) {
+	q := r.URL.Query()
+	opts := ListOptions{
+		PageToken: q.Get("page_token"),
+		Sort:      SortField(q.Get("sort")),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return ListOptions{}, validationError("limit must be a positive integer")
+		}
+		opts.Limit = n
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return ListOptions{}, validationError("order must be asc or desc")
+	}
+
+	if v := q.Get("completed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return ListOptions{}, validationError("completed must be true or false")
+		}
+		opts.Completed = &b
+	}
+
+	switch v := q.Get("list_id"); v {
+	case "":
+	case "inbox":
+		opts.Inbox = true
+	default:
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return ListOptions{}, validationError("list_id must be a list id or inbox")
+		}
+		opts.ListID = &id
+	}
+
+	switch v := q.Get("parent_id"); v {
+	case "":
+	case "none":
+		opts.TopLevel = true
+	default:
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return ListOptions{}, validationError("parent_id must be a todo id or none")
+		}
+		opts.ParentID = &id
+	}
+
+	if v := q.Get("tags"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Tags = append(opts.Tags, name)
+			}
+		}
+	}
+	switch q.Get("tag_match") {
+	case "", "all":
+	case "any":
+		opts.MatchAnyTag = true
+	default:
+		return ListOptions{}, validationError("tag_match must be all or any")
+	}
+
+	times := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &opts.CreatedAfter},
+		{"created_before", &opts.CreatedBefore},
+		{"completed_after", &opts.CompletedAfter},
+		{"completed_before", &opts.CompletedBefore},
+		{"due_after", &opts.DueAfter},
+		{"due_before", &opts.DueBefore},
+	}
+	for _, p := range times {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return ListOptions{}, validationError("%s must be an RFC 3339 time", p.name)
+		}
+		*p.dst = &t
+	}
+
+	return opts, nil
+}
+
+// writeError maps a service error onto an HTTP status and writes it as a
+// JSON error response. It is the only place domain errors are translated
+// to status codes; op names the failed operation for the server log.
+func writeError(w http.ResponseWriter, op string, err error) {
+	status, msg := errorStatus(op, err)
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// errorStatus returns the HTTP status for a service error and the
+// message to show the client. Server errors are logged instead of shown.
+func errorStatus(op string, err error) (int, string) {
+	var status int
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrValidation):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, ErrUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, ErrPreconditionFailed):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, ErrAborted):
+		status = http.StatusFailedDependency
+	case errors.Is(err, ErrTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	default:
+		status = http.StatusInternalServerError
+	}
+
+	if status >= http.StatusInternalServerError {
+		log.Printf("Failed to %s: %v", op, err)
+		return status, "failed to " + op
+	}
+	return status, err.Error()
 }
-writeJSON(w, http.StatusOK, todo)
+
+// writeTodo writes t as a JSON response with its version as the ETag.
+func writeTodo(w http.ResponseWriter, status int, t Todo) {
+	w.Header().Set("ETag", etag(t.Version))
+	writeJSON(w, status, t)
 }
 
+// etag formats a todo version as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the version named by the If-Match header, or zero
+// if there is no header or it is "*". The version is only ever sent as a
+// strong ETag, so anything else, including a weak ETag, cannot match the
+// current version and fails with ErrPreconditionFailed.
+func parseIfMatch(r *http.Request) (int64, error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	if strings.Contains(v, ",") {
+		return 0, validationError("If-Match must be a single ETag or *")
+	}
+	unquoted, ok := strings.CutPrefix(v, `"`)
+	if ok {
+		unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if !ok || err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: If-Match %s is not a version of this todo", ErrPreconditionFailed, v)
+	}
+	return version, nil
+}
 
 // writeJSON is a helper function to write JSON responses.
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -217,4 +716,4 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	if err := json.NewEncoder(w).Encode(v); err != nil {
 		fmt.Println("failed to write json:", err)
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,139 @@
+package todo
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// historyHandler handles GET /todos/{id}/history.
+// @Summary List the changes made to a todo
+// @Description Each event records who made the change, when, and the todo before and after it. Subtasks completed or trashed along with a todo have events of their own.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param limit query int false "Maximum number of events to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param actor_id query int false "Only changes made by this user"
+// @Param action query string false "Only changes of this kind: create, update, toggle, delete, restore, move, purge, list_create, list_update, list_delete, tag_create, tag_update or tag_delete"
+// @Param after query string false "Only changes made after this RFC 3339 time"
+// @Param before query string false "Only changes made before this RFC 3339 time"
+// @Success 200 {object} EventPage "Page of events, newest first"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/history [get]
+func (h *Handler) historyHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	f, err := parseEventFilter(r)
+	if err != nil {
+		writeError(w, "get todo history", err)
+		return
+	}
+	page, err := h.service.History(r.Context(), id, f)
+	if err != nil {
+		writeError(w, "get todo history", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// auditHandler handles GET /audit.
+// @Summary List the audit log
+// @Description Changes to every todo the caller can see, or could see when the change was made.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum number of events to return (default 50, max 500)"
+// @Param page_token query string false "Token from a previous response's next_page_token"
+// @Param todo_id query int false "Only changes to this todo"
+// @Param list_id query int false "Only changes to this list and the todos in it"
+// @Param actor_id query int false "Only changes made by this user"
+// @Param action query string false "Only changes of this kind: create, update, toggle, delete, restore, move, purge, list_create, list_update, list_delete, tag_create, tag_update or tag_delete"
+// @Param after query string false "Only changes made after this RFC 3339 time"
+// @Param before query string false "Only changes made before this RFC 3339 time"
+// @Success 200 {object} EventPage "Page of events, newest first"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /audit [get]
+func (h *Handler) auditHandler(w http.ResponseWriter, r *http.Request) {
+	f, err := parseEventFilter(r)
+	if err != nil {
+		writeError(w, "list audit events", err)
+		return
+	}
+	page, err := h.service.Events(r.Context(), f)
+	if err != nil {
+		writeError(w, "list audit events", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// parseEventFilter reads the GET /audit query parameters.
+func parseEventFilter(r *http.Request) (EventFilter, error) {
+	q := r.URL.Query()
+	f := EventFilter{
+		PageToken: q.Get("page_token"),
+		Action:    EventAction(q.Get("action")),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return EventFilter{}, validationError("limit must be a positive integer")
+		}
+		f.Limit = n
+	}
+
+	ids := []struct {
+		name string
+		dst  **int
+	}{
+		{"todo_id", &f.TodoID},
+		{"list_id", &f.ListID},
+		{"actor_id", &f.ActorID},
+	}
+	for _, p := range ids {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return EventFilter{}, validationError("%s must be an integer", p.name)
+		}
+		*p.dst = &id
+	}
+
+	times := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"after", &f.After},
+		{"before", &f.Before},
+	}
+	for _, p := range times {
+		v := q.Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return EventFilter{}, validationError("%s must be an RFC 3339 time", p.name)
+		}
+		*p.dst = &t
+	}
+
+	return f, nil
+}
@@ -0,0 +1,78 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// registerHandler handles POST /auth/register.
+// @Summary Register a new user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body CredentialsRequest true "Email and password (8 to 72 bytes)"
+// @Success 201 {object} TokenResponse "The new user and an access token"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 409 {object} map[string]string "Email already registered"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /auth/register [post]
+func (h *Handler) registerHandler(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	resp, err := h.accounts.Register(r.Context(), req)
+	if err != nil {
+		writeError(w, "register user", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// loginHandler handles POST /auth/login.
+// @Summary Log in and get an access token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body CredentialsRequest true "Email and password"
+// @Success 200 {object} TokenResponse "The user and an access token"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Invalid email or password"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /auth/login [post]
+func (h *Handler) loginHandler(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+	resp, err := h.accounts.Login(r.Context(), req)
+	if err != nil {
+		writeError(w, "log in", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// authenticate is middleware that requires a valid bearer token and
+// passes the authenticated user to the next handler in the request
+// context.
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing bearer token"})
+			return
+		}
+		ctx, err := h.accounts.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, "authenticate", err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
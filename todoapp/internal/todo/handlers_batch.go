@@ -0,0 +1,51 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// batchHandler handles POST /todos/batch.
+// @Summary Apply several todo operations in one transaction
+// @Description Runs create, update, toggle and delete operations in order and reports a result for each, with the status the operation would have had on its own. In all_or_nothing mode (the default) the first failure undoes the whole batch: the response has that operation's status and every other operation reports 424. In best_effort mode the operations that succeed are saved and the response is 200.
+// @Tags todos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param batch body BatchRequest true "Mode and operations"
+// @Success 200 {object} BatchResponse "Per-operation results"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/batch [post]
+func (h *Handler) batchHandler(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+
+	results, err := h.service.Batch(r.Context(), req)
+	if err != nil {
+		writeError(w, "apply batch", err)
+		return
+	}
+
+	resp := BatchResponse{Committed: true, Results: make([]BatchOperationResult, len(results))}
+	status := http.StatusOK
+	for i, res := range results {
+		out := BatchOperationResult{Op: res.Op, ID: res.ID, Status: http.StatusOK, Todo: res.Todo}
+		if res.Op == BatchCreate && res.Err == nil {
+			out.Status = http.StatusCreated
+		}
+		if res.Err != nil {
+			out.Status, out.Error = errorStatus(string(res.Op)+" todo", res.Err)
+			if req.Mode != BatchBestEffort && out.Status != http.StatusFailedDependency {
+				resp.Committed = false
+				status = out.Status
+			}
+		}
+		resp.Results[i] = out
+	}
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,120 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// heartbeatInterval is how often an idle event stream sends a comment,
+// to keep proxies from closing the connection.
+const heartbeatInterval = 15 * time.Second
+
+// eventsHandler handles GET /todos/events.
+// @Summary Stream changes to todos
+// @Description A Server-Sent Events stream of the changes to the todos, lists and tags the caller can see, as they are committed. Each event's type is the action (create, update, toggle, delete, restore, move, purge, list_create, list_update, list_delete, tag_create, tag_update or tag_delete) and its data the audit log event as JSON. Its id is a cursor rather than the audit log event id: changes commit out of id order, so it is the id up to which every event has been sent. A client that reconnects with Last-Event-ID first gets the events after the cursor, which may repeat some it already has; the id in the data tells them apart. If it missed more than 1000 events, it gets a reset event instead, with data {"action":"reset"}, and should reload what it shows.
+// @Tags audit
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "Replay the events after this cursor first"
+// @Param last_event_id query int false "Same as Last-Event-ID, for clients that cannot set headers"
+// @Success 200 {object} Event "Stream of events"
+// @Failure 400 {object} map[string]string "Invalid event id"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Router /todos/events [get]
+func (h *Handler) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("last_event_id")
+	}
+	var after int64
+	if id != "" {
+		var err error
+		after, err = strconv.ParseInt(id, 10, 64)
+		if err != nil || after < 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid last event id"})
+			return
+		}
+	}
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		writeError(w, "stream events", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	events := make(chan streamedEvent)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.service.Watch(ctx, after, func(e Event, cursor int64) error {
+			select {
+			case events <- streamedEvent{e, cursor}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case e := <-events:
+			err = writeEvent(w, e.Event, e.cursor)
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		case err := <-done:
+			// The status is sent; all that can be done is to end the
+			// stream, which the client resumes with Last-Event-ID.
+			if err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Failed to stream events: %v", err)
+			}
+			return
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// streamedEvent is an event Watch passed on, with the cursor to resume
+// from after it.
+type streamedEvent struct {
+	Event
+	cursor int64
+}
+
+// writeEvent writes e as a Server-Sent Event whose id is cursor, so that
+// the client resumes from it with Last-Event-ID.
+func writeEvent(w http.ResponseWriter, e Event, cursor int64) error {
+	var v any = e
+	if e.Action == EventReset {
+		v = map[string]EventAction{"action": e.Action}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", cursor, e.Action, data)
+	return err
+}
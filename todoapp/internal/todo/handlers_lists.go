@@ -0,0 +1,121 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// listsHandler handles GET /lists and POST /lists.
+// @Summary List all lists or create a new list
+// @Tags lists
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} TodoList "All lists, by name, with open and completed counts"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /lists [get]
+// @Accept json
+// @Param list body ListRequest true "List to create"
+// @Success 201 {object} TodoList "Newly created list"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Router /lists [post]
+func (h *Handler) listsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		lists, err := h.service.ListLists(r.Context())
+		if err != nil {
+			writeError(w, "list lists", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, lists)
+
+	case http.MethodPost:
+		var req ListRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		l, err := h.service.CreateList(r.Context(), req)
+		if err != nil {
+			writeError(w, "create list", err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, l)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// listItemHandler handles GET, PUT and DELETE /lists/{id}.
+// @Summary Get, rename, or delete a list
+// @Description Deleting a list moves its todos to the inbox, or deletes them with todos=delete.
+// @Tags lists
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} TodoList "List details"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "List not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /lists/{id} [get]
+// @Accept json
+// @Param list body ListRequest true "New list name"
+// @Success 200 {object} TodoList "Renamed list"
+// @Router /lists/{id} [put]
+// @Param todos query string false "What to do with the list's todos: inbox (default) or delete"
+// @Success 200 {object} map[string]string "Success message"
+// @Router /lists/{id} [delete]
+func (h *Handler) listItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		l, err := h.service.GetList(r.Context(), id)
+		if err != nil {
+			writeError(w, "get list", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, l)
+
+	case http.MethodPut:
+		var req ListRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		l, err := h.service.RenameList(r.Context(), id, req)
+		if err != nil {
+			writeError(w, "rename list", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, l)
+
+	case http.MethodDelete:
+		var deleteTodos bool
+		switch r.URL.Query().Get("todos") {
+		case "", "inbox":
+		case "delete":
+			deleteTodos = true
+		default:
+			writeError(w, "delete list", validationError("todos must be inbox or delete"))
+			return
+		}
+		if err := h.service.DeleteList(r.Context(), id, deleteTodos); err != nil {
+			writeError(w, "delete list", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "list deleted successfully"})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// acceptPatch lists the patch formats PATCH /todos/{id} accepts.
+const acceptPatch = string(MergePatch) + ", " + string(JSONPatch)
+
+// patchHandler handles PATCH /todos/{id}.
+// @Summary Patch a todo
+// @Description Applies an RFC 7396 merge patch (application/merge-patch+json) or an RFC 6902 JSON Patch (application/json-patch+json) to the todo's JSON representation, all at once or not at all. Only title, completed, due_at, remind_at, priority, list_id, parent_id and recurrence may change; the last five may be removed or set to null to clear them. A failed JSON Patch test operation gives 409. completed_at only changes when completed does.
+// @Tags todos
+// @Security BearerAuth
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param If-Match header string false "ETag of the version the patch applies to"
+// @Param patch body object true "Merge patch object or JSON Patch array"
+// @Success 200 {object} Todo "Patched todo"
+// @Header 200 {string} ETag "Version of the todo"
+// @Failure 400 {object} map[string]string "Invalid patch or patched todo"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 403 {object} map[string]string "Caller may not change the todo"
+// @Failure 404 {object} map[string]string "Todo not found"
+// @Failure 409 {object} map[string]string "Test operation failed or conflicting change"
+// @Failure 412 {object} map[string]string "The todo has changed since the If-Match version"
+// @Failure 415 {object} map[string]string "Unsupported patch format"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id} [patch]
+func (h *Handler) patchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format := PatchFormat(mediaType)
+	if format != MergePatch && format != JSONPatch {
+		w.Header().Set("Accept-Patch", acceptPatch)
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "Content-Type must be " + string(MergePatch) + " or " + string(JSONPatch)})
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		writeError(w, "patch todo", err)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid body"})
+		return
+	}
+
+	t, err := h.service.Patch(r.Context(), id, version, Patch{Format: format, Body: body})
+	if err != nil {
+		writeError(w, "patch todo", err)
+		return
+	}
+	writeTodo(w, http.StatusOK, t)
+}
@@ -0,0 +1,219 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// membersHandler handles GET /lists/{id}/members.
+// @Summary List the members of a list
+// @Tags sharing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {array} Member "Members, in the order they joined"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 404 {object} map[string]string "List not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /lists/{id}/members [get]
+func (h *Handler) membersHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	members, err := h.service.ListMembers(r.Context(), id)
+	if err != nil {
+		writeError(w, "list members", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, members)
+}
+
+// memberItemHandler handles PUT and DELETE /lists/{id}/members/{userID}.
+// @Summary Change a member's role or remove a member
+// @Description Only owners may change roles or remove other members; any member may remove themselves to leave the list. A list always keeps at least one owner.
+// @Tags sharing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param userID path int true "User ID of the member"
+// @Param member body MemberRequest true "New role: viewer, editor or owner"
+// @Success 200 {object} Member "Updated member"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 403 {object} map[string]string "Caller is not an owner of the list"
+// @Failure 404 {object} map[string]string "List or member not found"
+// @Failure 409 {object} map[string]string "The list would be left without an owner"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /lists/{id}/members/{userID} [put]
+// @Success 200 {object} map[string]string "Success message"
+// @Router /lists/{id}/members/{userID} [delete]
+func (h *Handler) memberItemHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	userID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user id"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req MemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		m, err := h.service.UpdateMember(r.Context(), id, userID, req)
+		if err != nil {
+			writeError(w, "update member", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, m)
+
+	case http.MethodDelete:
+		if err := h.service.RemoveMember(r.Context(), id, userID); err != nil {
+			writeError(w, "remove member", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "member removed successfully"})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// listInvitationsHandler handles GET and POST /lists/{id}/invitations.
+// @Summary List or send invitations to a list
+// @Description Only owners may see or send invitations. The invitee need not have registered yet.
+// @Tags sharing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {array} Invitation "Pending invitations, oldest first"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 403 {object} map[string]string "Caller is not an owner of the list"
+// @Failure 404 {object} map[string]string "List not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /lists/{id}/invitations [get]
+// @Accept json
+// @Param invitation body InvitationRequest true "Invitee email and role"
+// @Success 201 {object} Invitation "Newly created invitation"
+// @Failure 409 {object} map[string]string "Already a member or already invited"
+// @Router /lists/{id}/invitations [post]
+func (h *Handler) listInvitationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		invitations, err := h.service.ListSentInvitations(r.Context(), id)
+		if err != nil {
+			writeError(w, "list invitations", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, invitations)
+
+	case http.MethodPost:
+		var req InvitationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		inv, err := h.service.Invite(r.Context(), id, req)
+		if err != nil {
+			writeError(w, "create invitation", err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, inv)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// invitationsHandler handles GET /invitations.
+// @Summary List the invitations addressed to the caller
+// @Tags sharing
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Invitation "Pending invitations, oldest first"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /invitations [get]
+func (h *Handler) invitationsHandler(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.service.ListInvitations(r.Context())
+	if err != nil {
+		writeError(w, "list invitations", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, invitations)
+}
+
+// acceptInvitationHandler handles POST /invitations/{id}/accept.
+// @Summary Accept an invitation
+// @Description Makes the caller a member of the list with the invited role.
+// @Tags sharing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} Member "The caller's membership"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /invitations/{id}/accept [post]
+func (h *Handler) acceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	m, err := h.service.AcceptInvitation(r.Context(), id)
+	if err != nil {
+		writeError(w, "accept invitation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// invitationItemHandler handles DELETE /invitations/{id}.
+// @Summary Decline or withdraw an invitation
+// @Description The invitee may decline an invitation; the list's owners may withdraw it.
+// @Tags sharing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]string "Success message"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 403 {object} map[string]string "Caller is neither the invitee nor an owner of the list"
+// @Failure 404 {object} map[string]string "Invitation not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /invitations/{id} [delete]
+func (h *Handler) invitationItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+	if err := h.service.DeleteInvitation(r.Context(), id); err != nil {
+		writeError(w, "delete invitation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "invitation deleted successfully"})
+}
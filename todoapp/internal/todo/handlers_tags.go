@@ -0,0 +1,173 @@
+package todo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// tagsHandler handles GET /tags and POST /tags.
+// @Summary List all tags or create a new tag
+// @Tags tags
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} Tag "All tags, by name"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /tags [get]
+// @Accept json
+// @Param tag body TagRequest true "Tag to create"
+// @Success 201 {object} Tag "Newly created tag"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 409 {object} map[string]string "Tag name already exists"
+// @Router /tags [post]
+func (h *Handler) tagsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		tags, err := h.service.ListTags(r.Context())
+		if err != nil {
+			writeError(w, "list tags", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tags)
+
+	case http.MethodPost:
+		var req TagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		tag, err := h.service.CreateTag(r.Context(), req)
+		if err != nil {
+			writeError(w, "create tag", err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, tag)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// tagItemHandler handles PUT /tags/{id} and DELETE /tags/{id}.
+// @Summary Rename or delete a tag
+// @Description Deleting a tag detaches it from every todo.
+// @Tags tags
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param tag body TagRequest true "New tag name"
+// @Success 200 {object} Tag "Renamed tag"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Tag not found"
+// @Failure 409 {object} map[string]string "Tag name already exists"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /tags/{id} [put]
+// @Success 200 {object} map[string]string "Success message"
+// @Router /tags/{id} [delete]
+func (h *Handler) tagItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid id"})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req TagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+			return
+		}
+		tag, err := h.service.RenameTag(r.Context(), id, req)
+		if err != nil {
+			writeError(w, "rename tag", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tag)
+
+	case http.MethodDelete:
+		if err := h.service.DeleteTag(r.Context(), id); err != nil {
+			writeError(w, "delete tag", err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "tag deleted successfully"})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// attachTagHandler handles POST /todos/{id}/tags.
+// @Summary Attach a tag to a todo
+// @Description Attaching a tag the todo already carries is not an error.
+// @Tags todos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param tag body AttachTagRequest true "Tag to attach"
+// @Success 200 {object} Todo "Tagged todo"
+// @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 404 {object} map[string]string "Todo or tag not found"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/tags [post]
+func (h *Handler) attachTagHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+
+	var req AttachTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid json"})
+		return
+	}
+
+	todo, err := h.service.AttachTag(r.Context(), id, req)
+	if err != nil {
+		writeError(w, "attach tag", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, todo)
+}
+
+// detachTagHandler handles DELETE /todos/{id}/tags/{tagID}.
+// @Summary Detach a tag from a todo
+// @Tags todos
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Todo ID"
+// @Param tagID path int true "Tag ID"
+// @Success 200 {object} Todo "Updated todo"
+// @Failure 400 {object} map[string]string "Invalid ID format"
+// @Failure 404 {object} map[string]string "Todo not found or not tagged"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/{id}/tags/{tagID} [delete]
+func (h *Handler) detachTagHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+	tagID, err := strconv.Atoi(vars["tagID"])
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid ID format"})
+		return
+	}
+
+	todo, err := h.service.DetachTag(r.Context(), id, tagID)
+	if err != nil {
+		writeError(w, "detach tag", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, todo)
+}
@@ -0,0 +1,159 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxImportBytes is the largest file an import reads.
+const maxImportBytes = 32 << 20
+
+// exportHandler handles GET /todos/export.
+// @Summary Export todos
+// @Description Streams every todo matching the filters of GET /todos as one file. json is an array of todos as GET /todos returns them; csv has the columns id, title, completed, created_at, completed_at, due_at, remind_at, priority, list_id, parent_id, recurrence and tags; todotxt has one todo.txt line per todo, with the fields todo.txt has no syntax for as key:value pairs.
+// @Tags transfer
+// @Security BearerAuth
+// @Produce json
+// @Produce text/csv
+// @Produce plain
+// @Param format query string false "json (default), csv or todotxt"
+// @Param completed query bool false "Only todos with this completion state"
+// @Param created_after query string false "Only todos created after this RFC 3339 time"
+// @Param created_before query string false "Only todos created before this RFC 3339 time"
+// @Param list_id query string false "Only todos in this list, or inbox for todos in no list"
+// @Param tags query string false "Comma-separated tag names to filter by"
+// @Param sort query string false "Sort field (default id)"
+// @Param order query string false "Sort order: asc or desc"
+// @Success 200 {string} string "Todos in the requested format"
+// @Failure 400 {object} map[string]string "Invalid query parameters"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/export [get]
+func (h *Handler) exportHandler(w http.ResponseWriter, r *http.Request) {
+	format := FileFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatJSON
+	}
+	if err := format.validate(); err != nil {
+		writeError(w, "export todos", err)
+		return
+	}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, "export todos", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+format.filename()+`"`)
+	cw := &countingWriter{w: w}
+	if err := h.service.Export(r.Context(), format, opts, cw); err != nil {
+		if cw.n == 0 {
+			w.Header().Del("Content-Disposition")
+			writeError(w, "export todos", err)
+			return
+		}
+		// The status is sent; all that can be done is to cut the file
+		// short.
+		log.Printf("Failed to export todos: %v", err)
+	}
+}
+
+// importHandler handles POST /todos/import.
+// @Summary Import todos
+// @Description Reads a file of at most 32 MiB in the format of GET /todos/export and creates its todos in batches of 500 rows, each saved in a transaction of its own, keeping their completion state and timestamps. Each row is reported as created, skipped because a todo with the same title and created_at already exists or appears earlier in the file, or rejected with the reason. A parent_id refers to the id of an earlier row of the file. With dry_run=true nothing is saved. If the file cannot be read to the end or a batch cannot be saved, the rest of the file is not imported: the response has the status of the error, 400 for an unreadable file and 413 for one too large, and if earlier batches were saved it is the report of their rows with the error.
+// @Tags transfer
+// @Security BearerAuth
+// @Accept json
+// @Accept text/csv
+// @Accept plain
+// @Produce json
+// @Param format query string false "json (default), csv or todotxt"
+// @Param dry_run query bool false "Report what would be imported without saving anything"
+// @Param file body string true "The file to import"
+// @Success 200 {object} ImportReport "Outcome of every row"
+// @Failure 400 {object} map[string]string "Invalid request or unreadable file"
+// @Failure 401 {object} map[string]string "Missing or invalid token"
+// @Failure 413 {object} map[string]string "File too large"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /todos/import [post]
+func (h *Handler) importHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := ImportRequest{Format: FileFormat(q.Get("format"))}
+	if req.Format == "" {
+		req.Format = FormatJSON
+	}
+	if v := q.Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, "import todos", validationError("dry_run must be true or false"))
+			return
+		}
+		req.DryRun = b
+	}
+
+	body := &limitedBody{r: http.MaxBytesReader(w, r.Body, maxImportBytes)}
+	results, err := h.service.Import(r.Context(), req, body)
+	if err != nil && body.tooLarge {
+		err = fmt.Errorf("%w: an import file holds at most %d bytes", ErrTooLarge, maxImportBytes)
+	}
+	if err != nil && len(results) == 0 {
+		writeError(w, "import todos", err)
+		return
+	}
+
+	report := ImportReport{DryRun: req.DryRun, Rows: make([]ImportRowReport, len(results))}
+	for i, res := range results {
+		row := ImportRowReport{Row: res.Row, Status: res.Status, ID: res.ID, Title: res.Title}
+		switch res.Status {
+		case ImportCreated:
+			report.Created++
+		case ImportSkipped:
+			report.Skipped++
+		case ImportRejected:
+			report.Rejected++
+			_, row.Error = errorStatus("import todo", res.Err)
+		}
+		report.Rows[i] = row
+	}
+	status := http.StatusOK
+	if err != nil {
+		// The batches before the error were saved, so their rows are
+		// reported along with it.
+		status, report.Error = errorStatus("import todos", err)
+	}
+	writeJSON(w, status, report)
+}
+
+// limitedBody reads a request body cut off by http.MaxBytesReader, and
+// tells whether it was.
+type limitedBody struct {
+	r        io.Reader
+	tooLarge bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		b.tooLarge = true
+	}
+	return n, err
+}
+
+// countingWriter counts the bytes written through it, so a handler can
+// tell whether a streamed response has started.
+type countingWriter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
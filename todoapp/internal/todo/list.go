@@ -0,0 +1,315 @@
+package todo
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page size limits for List.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// SortField names a column todos can be ordered by.
+type SortField string
+
+const (
+	SortByID          SortField = "id"
+	SortByCreatedAt   SortField = "created_at"
+	SortByCompletedAt SortField = "completed_at"
+	SortByTitle       SortField = "title"
+	SortByDueAt       SortField = "due_at"
+	SortByPriority    SortField = "priority"
+	SortByPosition    SortField = "position"
+)
+
+// ListOptions controls which todos List returns and in which order.
+// The zero value lists the first DefaultPageSize todos ordered by id.
+type ListOptions struct {
+	// Limit is the maximum number of todos to return. Zero means
+	// DefaultPageSize.
+	Limit int
+	// PageToken continues a previous listing. It must come from
+	// Page.NextPageToken of a call with the same sort order.
+	PageToken string
+
+	// Completed, when set, keeps only todos with that completion state.
+	Completed *bool
+
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	CompletedAfter  *time.Time
+	CompletedBefore *time.Time
+	DueAfter        *time.Time
+	DueBefore       *time.Time
+
+	// Tags keeps only todos carrying all of the named tags, or any of
+	// them if MatchAnyTag is set. Names match ignoring case.
+	Tags        []string
+	MatchAnyTag bool
+
+	// ListID keeps only todos in that list; Inbox keeps only todos in
+	// no list. At most one of them may be set.
+	ListID *int
+	Inbox  bool
+
+	// ParentID keeps only subtasks of that todo; TopLevel keeps only
+	// todos without a parent. At most one of them may be set.
+	ParentID *int
+	TopLevel bool
+
+	// Trashed lists the trash instead: only todos that have been deleted
+	// and not yet purged.
+	Trashed bool
+
+	// Sort defaults to SortByID. Todos without a completed_at or due_at
+	// sort last by that field in either direction.
+	Sort       SortField
+	Descending bool
+}
+
+// Page is one page of List results.
+type Page struct {
+	Todos []Todo `json:"todos"`
+	// NextPageToken is empty on the last page.
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// Validate checks the options and reports problems as ErrValidation.
+func (o ListOptions) Validate() error {
+	if o.Limit < 0 || o.Limit > MaxPageSize {
+		return validationError("limit must be between 1 and %d", MaxPageSize)
+	}
+	switch o.sortField() {
+	case SortByID, SortByCreatedAt, SortByCompletedAt, SortByTitle, SortByDueAt, SortByPriority, SortByPosition:
+	default:
+		return validationError("cannot sort by %q", o.Sort)
+	}
+	if o.ListID != nil && o.Inbox {
+		return validationError("cannot filter by a list and the inbox at once")
+	}
+	if o.ParentID != nil && o.TopLevel {
+		return validationError("cannot filter by a parent and top-level todos at once")
+	}
+	_, err := o.cursor()
+	return err
+}
+
+func (o ListOptions) limit() int {
+	if o.Limit == 0 {
+		return DefaultPageSize
+	}
+	return o.Limit
+}
+
+func (o ListOptions) sortField() SortField {
+	if o.Sort == "" {
+		return SortByID
+	}
+	return o.Sort
+}
+
+// matches reports whether t passes the filters in o.
+func (o ListOptions) matches(t Todo) bool {
+	if (t.DeletedAt != nil) != o.Trashed {
+		return false
+	}
+	if o.Completed != nil && t.Completed != *o.Completed {
+		return false
+	}
+	if o.CreatedAfter != nil && !t.CreatedAt.After(*o.CreatedAfter) {
+		return false
+	}
+	if o.CreatedBefore != nil && !t.CreatedAt.Before(*o.CreatedBefore) {
+		return false
+	}
+	if o.CompletedAfter != nil && (t.CompletedAt == nil || !t.CompletedAt.After(*o.CompletedAfter)) {
+		return false
+	}
+	if o.CompletedBefore != nil && (t.CompletedAt == nil || !t.CompletedAt.Before(*o.CompletedBefore)) {
+		return false
+	}
+	if o.DueAfter != nil && (t.DueAt == nil || !t.DueAt.After(*o.DueAfter)) {
+		return false
+	}
+	if o.DueBefore != nil && (t.DueAt == nil || !t.DueAt.Before(*o.DueBefore)) {
+		return false
+	}
+	if o.ListID != nil && (t.ListID == nil || *t.ListID != *o.ListID) {
+		return false
+	}
+	if o.Inbox && t.ListID != nil {
+		return false
+	}
+	if o.ParentID != nil && (t.ParentID == nil || *t.ParentID != *o.ParentID) {
+		return false
+	}
+	if o.TopLevel && t.ParentID != nil {
+		return false
+	}
+	if len(o.Tags) > 0 && !t.hasTags(o.Tags, !o.MatchAnyTag) {
+		return false
+	}
+	return true
+}
+
+// compare orders a and b by the sort field in o, breaking ties by id.
+func (o ListOptions) compare(a, b Todo) int {
+	var c int
+	switch o.sortField() {
+	case SortByCreatedAt:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case SortByCompletedAt, SortByDueAt:
+		// Nulls last regardless of direction.
+		av, bv := a.sortTime(o.sortField()), b.sortTime(o.sortField())
+		switch {
+		case av == nil && bv != nil:
+			return 1
+		case av != nil && bv == nil:
+			return -1
+		case av != nil:
+			c = av.Compare(*bv)
+		}
+	case SortByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	case SortByPriority:
+		c = cmp.Compare(a.Priority, b.Priority)
+	case SortByPosition:
+		c = cmp.Compare(a.Position, b.Position)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if o.Descending {
+		c = -c
+	}
+	return c
+}
+
+// sortTime returns the nullable time field named by f.
+func (t Todo) sortTime(f SortField) *time.Time {
+	switch f {
+	case SortByCompletedAt:
+		return t.CompletedAt
+	case SortByDueAt:
+		return t.DueAt
+	}
+	return nil
+}
+
+// pageCursor is the decoded form of a page token: the sort key of the
+// last todo on the previous page.
+type pageCursor struct {
+	Sort       SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	ID         int       `json:"id"`
+	// Value is the sort column value; nil for a NULL completed_at or
+	// due_at.
+	Value *string `json:"v,omitempty"`
+}
+
+// cursor decodes PageToken. It returns nil if there is no token.
+func (o ListOptions) cursor() (*pageCursor, error) {
+	if o.PageToken == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(o.PageToken)
+	if err != nil {
+		return nil, validationError("invalid page token")
+	}
+	var c pageCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, validationError("invalid page token")
+	}
+	if c.Sort != o.sortField() || c.Descending != o.Descending {
+		return nil, validationError("page token does not match the requested sort order")
+	}
+	if _, err := c.key(); err != nil {
+		return nil, validationError("invalid page token")
+	}
+	return &c, nil
+}
+
+// key returns a Todo carrying the cursor's sort key, so it can be
+// compared with ListOptions.compare.
+func (c *pageCursor) key() (Todo, error) {
+	t := Todo{ID: c.ID}
+	switch c.Sort {
+	case SortByCreatedAt, SortByCompletedAt, SortByDueAt:
+		if c.Value == nil {
+			break
+		}
+		v, err := time.Parse(time.RFC3339Nano, *c.Value)
+		if err != nil {
+			return Todo{}, err
+		}
+		switch c.Sort {
+		case SortByCreatedAt:
+			t.CreatedAt = v
+		case SortByCompletedAt:
+			t.CompletedAt = &v
+		case SortByDueAt:
+			t.DueAt = &v
+		}
+	case SortByTitle:
+		if c.Value != nil {
+			t.Title = *c.Value
+		}
+	case SortByPriority, SortByPosition:
+		if c.Value == nil {
+			return Todo{}, errors.New("missing cursor value")
+		}
+		n, err := strconv.ParseInt(*c.Value, 10, 64)
+		if err != nil {
+			return Todo{}, err
+		}
+		t.Priority, t.Position = int(n), n
+	}
+	return t, nil
+}
+
+// nextPageToken encodes the sort key of last as a page token.
+func (o ListOptions) nextPageToken(last Todo) string {
+	c := pageCursor{Sort: o.sortField(), Descending: o.Descending, ID: last.ID}
+	var v string
+	switch c.Sort {
+	case SortByCreatedAt:
+		v = last.CreatedAt.Format(time.RFC3339Nano)
+		c.Value = &v
+	case SortByCompletedAt, SortByDueAt:
+		if at := last.sortTime(c.Sort); at != nil {
+			v = at.Format(time.RFC3339Nano)
+			c.Value = &v
+		}
+	case SortByTitle:
+		v = last.Title
+		c.Value = &v
+	case SortByPriority:
+		v = strconv.Itoa(last.Priority)
+		c.Value = &v
+	case SortByPosition:
+		v = strconv.FormatInt(last.Position, 10)
+		c.Value = &v
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// newPage builds a Page from up to limit()+1 ordered todos; the extra
+// todo, if present, only signals that another page exists.
+func newPage(todos []Todo, opts ListOptions) Page {
+	if todos == nil {
+		todos = []Todo{}
+	}
+	if len(todos) <= opts.limit() {
+		return Page{Todos: todos}
+	}
+	todos = todos[:opts.limit()]
+	return Page{Todos: todos, NextPageToken: opts.nextPageToken(todos[len(todos)-1])}
+}
@@ -0,0 +1,71 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxListNameLength is the longest list name accepted, in characters.
+const maxListNameLength = 100
+
+// TodoList is a named list (project) that todos can belong to. Todos
+// without a list are in their owner's inbox. Lists can be shared; the
+// list's todos are visible to all of its members.
+type TodoList struct {
+	ID int `json:"id" example:"1"`
+	// OwnerID is the user who created the list.
+	OwnerID   int       `json:"owner_id" example:"1"`
+	Name      string    `json:"name" example:"Home"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	// Role is the caller's role in the list.
+	Role Role `json:"role" example:"owner"`
+	// OpenCount and CompletedCount count the todos in the list.
+	OpenCount      int `json:"open_count" example:"3"`
+	CompletedCount int `json:"completed_count" example:"5"`
+}
+
+// ListRequest represents the request body for creating or renaming a
+// list.
+type ListRequest struct {
+	Name string `json:"name" example:"Home"`
+}
+
+// normalizeListName trims name and checks it is a valid list name.
+func normalizeListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", validationError("list name is required")
+	case utf8.RuneCountInString(name) > maxListNameLength:
+		return "", validationError("list name must be at most %d characters", maxListNameLength)
+	}
+	return name, nil
+}
+
+// NullableInt is an optional JSON integer that distinguishes an absent
+// field (Set is false) from an explicit null (Set is true, Value is nil).
+type NullableInt struct {
+	Set   bool
+	Value *int
+}
+
+func (n *NullableInt) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		n.Value = nil
+		return nil
+	}
+	var v int
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+	return nil
+}
+
+func (n NullableInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
@@ -1,12 +1,93 @@
 package todo
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
 
 // Todo represents a todo item.
 type Todo struct {
 	ID          int        `json:"id" example:"1"`
+	OwnerID     int        `json:"owner_id" example:"1"`
 	Title       string     `json:"title" example:"Buy groceries"`
 	Completed   bool       `json:"completed" example:"false"`
 	CreatedAt   time.Time  `json:"created_at" example:"2023-01-01T00:00:00Z"`
 	CompletedAt *time.Time `json:"completed_at,omitempty" example:"2023-01-02T15:04:05Z"`
-}
\ No newline at end of file
+	DueAt       *time.Time `json:"due_at,omitempty" example:"2023-01-03T17:00:00+01:00"`
+	RemindAt    *time.Time `json:"remind_at,omitempty" example:"2023-01-03T09:00:00+01:00"`
+	// Priority is PriorityNone (0) through PriorityHigh (3).
+	Priority int `json:"priority" example:"2"`
+	// Position orders the todos of a list or inbox manually; change it
+	// with the move endpoint. A todo filed in another list goes last.
+	Position int64 `json:"position" example:"1024"`
+	// ListID is the list the todo belongs to, or nil for the inbox.
+	ListID *int `json:"list_id,omitempty" example:"1"`
+	// ParentID is the todo this one is a subtask of.
+	ParentID *int `json:"parent_id,omitempty" example:"4"`
+	// Recurrence is the todo's recurrence rule. Completing a recurring
+	// todo creates its next occurrence, which takes over the rule.
+	Recurrence string `json:"recurrence,omitempty" example:"FREQ=WEEKLY;BYDAY=MO,TH"`
+	// Progress is set when the todo has subtasks.
+	Progress *Progress `json:"progress,omitempty"`
+	// Tags are ordered by name.
+	Tags []Tag `json:"tags"`
+	// DeletedAt is set while the todo is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2023-01-04T10:00:00Z"`
+	// Version increases with every change to the todo's own fields. It
+	// is also sent as the todo's ETag; send it back in If-Match to make
+	// a change fail if someone else changed the todo first.
+	Version int64 `json:"version" example:"3"`
+}
+
+// CreateTodoRequest represents the request body for creating a todo.
+type CreateTodoRequest struct {
+	Title      string     `json:"title" example:"Buy groceries"`
+	DueAt      *time.Time `json:"due_at,omitempty" example:"2023-01-03T17:00:00+01:00"`
+	RemindAt   *time.Time `json:"remind_at,omitempty" example:"2023-01-03T09:00:00+01:00"`
+	Priority   int        `json:"priority,omitempty" example:"2"`
+	ListID     *int       `json:"list_id,omitempty" example:"1"`
+	ParentID   *int       `json:"parent_id,omitempty" example:"4"`
+	Recurrence string     `json:"recurrence,omitempty" example:"FREQ=DAILY;INTERVAL=2"`
+}
+
+// UpdateTodoRequest represents the request body for updating a todo.
+// Omitted fields are left unchanged; due_at and remind_at are cleared
+// by sending null, a null list_id moves the todo to the inbox and a null
+// parent_id makes it a top-level todo. An empty recurrence stops the todo
+// recurring.
+type UpdateTodoRequest struct {
+	Title      *string      `json:"title,omitempty" example:"Updated title"`
+	Completed  *bool        `json:"completed,omitempty" example:"true"`
+	Priority   *int         `json:"priority,omitempty" example:"3"`
+	DueAt      NullableTime `json:"due_at,omitzero" swaggertype:"string" format:"date-time" example:"2023-01-03T17:00:00+01:00"`
+	RemindAt   NullableTime `json:"remind_at,omitzero" swaggertype:"string" format:"date-time" example:"2023-01-03T09:00:00+01:00"`
+	ListID     NullableInt  `json:"list_id,omitzero" swaggertype:"integer" example:"1"`
+	ParentID   NullableInt  `json:"parent_id,omitzero" swaggertype:"integer" example:"4"`
+	Recurrence *string      `json:"recurrence,omitempty" example:"FREQ=WEEKLY;BYDAY=MO,TH;X-TZID=Europe/Berlin"`
+}
+
+// NullableTime is an optional JSON time that distinguishes an absent
+// field (Set is false) from an explicit null (Set is true, Value is nil).
+type NullableTime struct {
+	Set   bool
+	Value *time.Time
+}
+
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(data, []byte("null")) {
+		n.Value = nil
+		return nil
+	}
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return err
+	}
+	n.Value = &t
+	return nil
+}
+
+func (n NullableTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
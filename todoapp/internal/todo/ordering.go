@@ -0,0 +1,121 @@
+package todo
+
+import "errors"
+
+// Priority levels. Higher values are more important.
+const (
+	PriorityNone   = 0
+	PriorityLow    = 1
+	PriorityMedium = 2
+	PriorityHigh   = 3
+)
+
+// positionGap is the spacing between consecutive positions when todos
+// are appended or renumbered, leaving room for moves in between.
+const positionGap int64 = 1024
+
+// Positions are ordered within a scope: the todos of a list, or the todos
+// in one owner's inbox. Inserting or moving a todo holds the
+// pg_advisory_xact_lock(class, id) of the scope it reads positions from,
+// with these classes for lists and inboxes.
+const (
+	listPositionLock  int32 = 0x706f736c // "posl"
+	inboxPositionLock int32 = 0x706f7369 // "posi"
+)
+
+// positionScope is a set of todos whose positions are ordered against
+// each other.
+type positionScope struct {
+	list bool // whether id is a list id rather than an owner id
+	id   int
+}
+
+// scopeOf returns the position scope of t.
+func scopeOf(t Todo) positionScope {
+	if t.ListID != nil {
+		return positionScope{list: true, id: *t.ListID}
+	}
+	return positionScope{id: t.OwnerID}
+}
+
+// contains reports whether t is in s.
+func (s positionScope) contains(t Todo) bool {
+	return scopeOf(t) == s
+}
+
+// where returns the SQL condition that a todo is in s, with arg the
+// placeholder bound to s.id.
+func (s positionScope) where(arg string) string {
+	if s.list {
+		return `list_id = ` + arg
+	}
+	return `list_id IS NULL AND owner_id = ` + arg
+}
+
+// lockKey returns the advisory lock keys of s.
+func (s positionScope) lockKey() (class, id int32) {
+	if s.list {
+		return listPositionLock, int32(s.id)
+	}
+	return inboxPositionLock, int32(s.id)
+}
+
+// errOtherScope reports a move of todo id next to an anchor in another
+// list or inbox.
+func errOtherScope(id, anchorID int) error {
+	return validationError("todo %d is not in the same list as todo %d", id, anchorID)
+}
+
+// errNoGap means two neighbouring positions are adjacent and the todos
+// must be renumbered before another todo fits between them.
+var errNoGap = errors.New("no gap between positions")
+
+// MoveRequest represents the request body for moving a todo. Exactly one
+// of Before and After names the anchor todo.
+type MoveRequest struct {
+	Before *int `json:"before,omitempty" example:"3"`
+	After  *int `json:"after,omitempty"`
+}
+
+func validatePriority(p int) error {
+	if p < PriorityNone || p > PriorityHigh {
+		return validationError("priority must be between %d and %d", PriorityNone, PriorityHigh)
+	}
+	return nil
+}
+
+// anchor validates req for moving todo id and returns the anchor id and
+// side.
+func (req MoveRequest) anchor(id int) (anchorID int, after bool, err error) {
+	switch {
+	case req.Before != nil && req.After != nil, req.Before == nil && req.After == nil:
+		return 0, false, validationError("exactly one of before and after is required")
+	case req.After != nil:
+		anchorID, after = *req.After, true
+	default:
+		anchorID = *req.Before
+	}
+	if anchorID == id {
+		return 0, false, validationError("cannot move a todo relative to itself")
+	}
+	return anchorID, after, nil
+}
+
+// endPosition is the position just past the anchor when it has no
+// neighbour on that side.
+func endPosition(anchor int64, after bool) int64 {
+	if after {
+		return anchor + positionGap
+	}
+	return anchor - positionGap
+}
+
+// midPosition returns the position halfway between a and b, or errNoGap
+// if there is no integer strictly between them.
+func midPosition(a, b int64) (int64, error) {
+	lo, hi := min(a, b), max(a, b)
+	if hi-lo < 2 {
+		return 0, errNoGap
+	}
+	return lo + (hi-lo)/2, nil
+}
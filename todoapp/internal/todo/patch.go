@@ -0,0 +1,504 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PatchFormat is the media type of a patch document.
+type PatchFormat string
+
+const (
+	// MergePatch is an RFC 7396 JSON merge patch.
+	MergePatch PatchFormat = "application/merge-patch+json"
+	// JSONPatch is an RFC 6902 JSON Patch.
+	JSONPatch PatchFormat = "application/json-patch+json"
+)
+
+// Patch is a patch document for the JSON representation of a todo. The
+// fields of UpdateTodoRequest may be changed; due_at, remind_at, list_id,
+// parent_id and recurrence may also be removed or set to null to clear
+// them. Every other field is read-only and must be left as it is, though
+// a JSON Patch may test it.
+type Patch struct {
+	Format PatchFormat
+	Body   []byte
+}
+
+// patchableFields are the fields a patch may change, and clearableFields
+// those of them it may also clear.
+var (
+	patchableFields = []string{"title", "completed", "due_at", "remind_at", "priority", "list_id", "parent_id", "recurrence"}
+	clearableFields = []string{"due_at", "remind_at", "list_id", "parent_id", "recurrence"}
+	readOnlyFields  = []string{"id", "owner_id", "created_at", "completed_at", "position", "progress", "tags", "deleted_at", "version"}
+)
+
+// patchOp is one operation of a JSON Patch.
+type patchOp struct {
+	Op   string  `json:"op"`
+	Path string  `json:"path"`
+	From *string `json:"from"`
+	// Value is nil if the member is missing and "null" if it is null.
+	Value json.RawMessage `json:"value"`
+}
+
+// validate checks that the patch is well-formed, before any todo is read.
+func (p Patch) validate() error {
+	switch p.Format {
+	case MergePatch:
+		var v any
+		if err := decodeJSON(p.Body, &v); err != nil {
+			return validationError("invalid merge patch: %v", err)
+		}
+		return nil
+	case JSONPatch:
+		_, err := p.operations()
+		return err
+	default:
+		return validationError("patch format must be %s or %s", MergePatch, JSONPatch)
+	}
+}
+
+// operations decodes and checks the operations of a JSON Patch.
+func (p Patch) operations() ([]patchOp, error) {
+	var ops []patchOp
+	if err := decodeJSON(p.Body, &ops); err != nil {
+		return nil, validationError("a JSON Patch must be an array of operations")
+	}
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, validationError("operation %d: %s needs a value", i, op.Op)
+			}
+		case "move", "copy":
+			if op.From == nil {
+				return nil, validationError("operation %d: %s needs from", i, op.Op)
+			}
+			if _, err := parsePointer(*op.From); err != nil {
+				return nil, validationError("operation %d: %v", i, err)
+			}
+		case "remove":
+		default:
+			return nil, validationError("operation %d: op must be add, remove, replace, move, copy or test", i)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, validationError("operation %d: %v", i, err)
+		}
+	}
+	return ops, nil
+}
+
+// changes applies the patch to t and returns the resulting changes as an
+// update request holding only the fields that changed.
+func (p Patch) changes(t Todo) (UpdateTodoRequest, error) {
+	before, err := todoDocument(t)
+	if err != nil {
+		return UpdateTodoRequest{}, err
+	}
+	// The patch works on a copy of its own, since JSON Patch changes it in
+	// place.
+	doc, _ := todoDocument(t)
+
+	var patched any
+	switch p.Format {
+	case MergePatch:
+		var patch any
+		if err := decodeJSON(p.Body, &patch); err != nil {
+			return UpdateTodoRequest{}, validationError("invalid merge patch: %v", err)
+		}
+		patched = mergePatch(doc, patch)
+	case JSONPatch:
+		ops, err := p.operations()
+		if err != nil {
+			return UpdateTodoRequest{}, err
+		}
+		if patched, err = applyJSONPatch(doc, ops); err != nil {
+			return UpdateTodoRequest{}, err
+		}
+	default:
+		return UpdateTodoRequest{}, p.validate()
+	}
+
+	result, ok := patched.(map[string]any)
+	if !ok {
+		return UpdateTodoRequest{}, validationError("the patched todo must be a JSON object")
+	}
+	return patchedChanges(before, result)
+}
+
+// todoDocument returns the JSON representation of t as a generic value,
+// with the clearable fields present even when they are empty so that
+// patches can replace them.
+func todoDocument(t Todo) (map[string]any, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := decodeJSON(data, &doc); err != nil {
+		return nil, err
+	}
+	for _, name := range clearableFields {
+		if _, ok := doc[name]; !ok {
+			doc[name] = nil
+		}
+	}
+	if doc["recurrence"] == nil {
+		doc["recurrence"] = ""
+	}
+	return doc, nil
+}
+
+// patchedChanges compares a todo document before and after a patch and
+// turns the differences into an update request, rejecting changes to
+// fields that cannot be changed.
+func patchedChanges(before, after map[string]any) (UpdateTodoRequest, error) {
+	for name, v := range after {
+		if slices.Contains(patchableFields, name) {
+			continue
+		}
+		if !slices.Contains(readOnlyFields, name) {
+			return UpdateTodoRequest{}, validationError("todos have no field %q", name)
+		}
+		if old, ok := before[name]; !ok || !jsonEqual(old, v) {
+			return UpdateTodoRequest{}, validationError("%s is read-only", name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok && !slices.Contains(patchableFields, name) {
+			return UpdateTodoRequest{}, validationError("%s is read-only", name)
+		}
+	}
+
+	var req UpdateTodoRequest
+	for _, name := range patchableFields {
+		v, ok := after[name]
+		if !ok {
+			if !slices.Contains(clearableFields, name) {
+				return UpdateTodoRequest{}, validationError("%s cannot be removed or null", name)
+			}
+			v = nil
+		}
+		if v == nil && name == "recurrence" {
+			v = ""
+		}
+		if jsonEqual(before[name], v) {
+			continue
+		}
+		if v == nil && !slices.Contains(clearableFields, name) {
+			return UpdateTodoRequest{}, validationError("%s must not be null", name)
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return UpdateTodoRequest{}, err
+		}
+		var dst any
+		switch name {
+		case "title":
+			dst = &req.Title
+		case "completed":
+			dst = &req.Completed
+		case "due_at":
+			dst = &req.DueAt
+		case "remind_at":
+			dst = &req.RemindAt
+		case "priority":
+			dst = &req.Priority
+		case "list_id":
+			dst = &req.ListID
+		case "parent_id":
+			dst = &req.ParentID
+		case "recurrence":
+			dst = &req.Recurrence
+		}
+		if err := json.Unmarshal(data, dst); err != nil {
+			return UpdateTodoRequest{}, validationError("%s cannot be %s", name, data)
+		}
+	}
+	return req, nil
+}
+
+// mergePatch applies an RFC 7396 merge patch to target.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = make(map[string]any)
+	}
+	for name, v := range p {
+		if v == nil {
+			delete(t, name)
+		} else {
+			t[name] = mergePatch(t[name], v)
+		}
+	}
+	return t
+}
+
+// applyJSONPatch applies the operations of an RFC 6902 JSON Patch to doc
+// in order. doc is changed in place, so on error it must be discarded.
+func applyJSONPatch(doc any, ops []patchOp) (any, error) {
+	for _, op := range ops {
+		var err error
+		if doc, err = applyPatchOp(doc, op); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOp(doc any, op patchOp) (any, error) {
+	path, _ := parsePointer(op.Path)
+	var value any
+	if op.Value != nil {
+		if err := decodeJSON(op.Value, &value); err != nil {
+			return nil, validationError("invalid value: %v", err)
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return addAt(doc, path, value)
+	case "remove":
+		doc, _, err := removeAt(doc, path)
+		return doc, err
+	case "replace":
+		doc, _, err := removeAt(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, value)
+	case "move":
+		from, _ := parsePointer(*op.From)
+		if len(path) > len(from) && slices.Equal(path[:len(from)], from) {
+			return nil, validationError("cannot move %s into itself", *op.From)
+		}
+		doc, v, err := removeAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, v)
+	case "copy":
+		from, _ := parsePointer(*op.From)
+		v, err := getAt(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		// Round-trip the value so the copy shares nothing with it.
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := decodeJSON(data, &v); err != nil {
+			return nil, err
+		}
+		return addAt(doc, path, v)
+	case "test":
+		v, err := getAt(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(v, value) {
+			return nil, fmt.Errorf("%w: test of %s failed", ErrConflict, op.Path)
+		}
+		return doc, nil
+	}
+	return nil, validationError("unknown op %q", op.Op)
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its reference
+// tokens.
+func parsePointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("path %q must be empty or start with /", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// getAt returns the value at path in doc.
+func getAt(doc any, path []string) (any, error) {
+	for _, tok := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[tok]
+			if !ok {
+				return nil, missingPath(path)
+			}
+			doc = v
+		case []any:
+			i, err := arrayIndex(tok, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, missingPath(path)
+		}
+	}
+	return doc, nil
+}
+
+// atParent calls f with the container holding the last token of path,
+// which must not be empty, and returns doc with the container f returns
+// in its place.
+func atParent(doc any, path []string, f func(container any, tok string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return f(doc, path[0])
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		child, ok := c[path[0]]
+		if !ok {
+			return nil, missingPath(path)
+		}
+		child, err := atParent(child, path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		c[path[0]] = child
+		return c, nil
+	case []any:
+		i, err := arrayIndex(path[0], len(c)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := atParent(c[i], path[1:], f)
+		if err != nil {
+			return nil, err
+		}
+		c[i] = child
+		return c, nil
+	}
+	return nil, missingPath(path)
+}
+
+// addAt adds value at path, replacing an object member or inserting
+// into an array.
+func addAt(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return atParent(doc, path, func(container any, tok string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[tok] = value
+			return c, nil
+		case []any:
+			i := len(c)
+			if tok != "-" {
+				var err error
+				if i, err = arrayIndex(tok, len(c)); err != nil {
+					return nil, err
+				}
+			}
+			return slices.Insert(c, i, value), nil
+		}
+		return nil, missingPath(path)
+	})
+}
+
+// removeAt removes the value at path and returns it.
+func removeAt(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, validationError("cannot remove the whole todo")
+	}
+	var removed any
+	doc, err := atParent(doc, path, func(container any, tok string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			v, ok := c[tok]
+			if !ok {
+				return nil, missingPath(path)
+			}
+			removed = v
+			delete(c, tok)
+			return c, nil
+		case []any:
+			i, err := arrayIndex(tok, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return slices.Delete(c, i, i+1), nil
+		}
+		return nil, missingPath(path)
+	})
+	return doc, removed, err
+}
+
+// arrayIndex parses an array index token no greater than max.
+func arrayIndex(tok string, max int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') {
+		return 0, validationError("%q is not an array index", tok)
+	}
+	if i > max {
+		return 0, validationError("array index %d is out of range", i)
+	}
+	return i, nil
+}
+
+func missingPath(path []string) error {
+	return validationError("path /%s does not exist", strings.Join(path, "/"))
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, comparing
+// numbers by value.
+func jsonEqual(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, jsonEqual)
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	}
+	return a == b
+}
+
+// decodeJSON unmarshals data into v, keeping numbers as json.Number and
+// rejecting trailing data.
+func decodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
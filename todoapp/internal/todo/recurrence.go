@@ -0,0 +1,250 @@
+package todo
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRecurrenceSteps bounds the occurrences stepped over when a recurring
+// todo is completed long after its due date.
+const maxRecurrenceSteps = 10000
+
+// Recurrence frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// recurrence is a parsed recurrence rule, a subset of the RFC 5545 RRULE
+// syntax:
+//
+//	FREQ=DAILY;INTERVAL=2
+//	FREQ=WEEKLY;BYDAY=MO,TH
+//	FREQ=MONTHLY
+//	FREQ=DAILY;INTERVAL=3;X-FROM=COMPLETION
+//	FREQ=WEEKLY;BYDAY=MO;X-TZID=Europe/Berlin
+//
+// X-FROM=COMPLETION is an extension that schedules the next occurrence
+// INTERVAL days after the todo is completed rather than after its due
+// date. X-TZID is an extension naming the IANA time zone in which days,
+// weekdays and days of the month are counted, so that occurrences keep
+// their local time of day across daylight saving time changes. Without
+// it they are counted in UTC.
+type recurrence struct {
+	freq     string
+	interval int
+	// byDay is sorted Monday first.
+	byDay          []time.Weekday
+	fromCompletion bool
+	// tzid names loc, or is empty for UTC.
+	tzid string
+	loc  *time.Location
+}
+
+// parseRecurrence parses and validates a recurrence rule.
+func parseRecurrence(s string) (recurrence, error) {
+	rec := recurrence{interval: 1, loc: time.UTC}
+	seen := make(map[string]bool)
+	s = strings.TrimSpace(s)
+	if len(s) >= len("RRULE:") && strings.EqualFold(s[:len("RRULE:")], "RRULE:") {
+		s = s[len("RRULE:"):]
+	}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return recurrence{}, validationError("invalid recurrence rule part %q", part)
+		}
+		// Time zone names are the only case-sensitive values.
+		name = strings.ToUpper(name)
+		if name != "X-TZID" {
+			value = strings.ToUpper(value)
+		}
+		if seen[name] {
+			return recurrence{}, validationError("recurrence rule repeats %s", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly:
+				rec.freq = value
+			default:
+				return recurrence{}, validationError("recurrence FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 1000 {
+				return recurrence{}, validationError("recurrence INTERVAL must be between 1 and 1000")
+			}
+			rec.interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := weekdays[day]
+				if !ok {
+					return recurrence{}, validationError("invalid recurrence weekday %q", day)
+				}
+				if !slices.Contains(rec.byDay, wd) {
+					rec.byDay = append(rec.byDay, wd)
+				}
+			}
+			slices.SortFunc(rec.byDay, func(a, b time.Weekday) int { return weekdayIndex(a) - weekdayIndex(b) })
+		case "X-FROM":
+			if value != "COMPLETION" {
+				return recurrence{}, validationError("recurrence X-FROM must be COMPLETION")
+			}
+			rec.fromCompletion = true
+		case "X-TZID":
+			// Local would depend on the server's time zone.
+			loc, err := time.LoadLocation(value)
+			if err != nil || value == "Local" {
+				return recurrence{}, validationError("unknown recurrence time zone %q", value)
+			}
+			rec.tzid, rec.loc = value, loc
+		default:
+			return recurrence{}, validationError("unsupported recurrence rule part %s", name)
+		}
+	}
+
+	switch {
+	case rec.freq == "":
+		return recurrence{}, validationError("recurrence FREQ is required")
+	case rec.byDay != nil && rec.freq != FreqWeekly:
+		return recurrence{}, validationError("recurrence BYDAY requires FREQ=WEEKLY")
+	case rec.fromCompletion && rec.freq != FreqDaily:
+		return recurrence{}, validationError("recurrence X-FROM=COMPLETION requires FREQ=DAILY")
+	}
+	return rec, nil
+}
+
+// normalizeRecurrence validates a recurrence rule from a request and
+// returns it in canonical form. An empty rule means no recurrence.
+func normalizeRecurrence(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	rec, err := parseRecurrence(s)
+	if err != nil {
+		return "", err
+	}
+	return rec.String(), nil
+}
+
+// String formats the rule in canonical form.
+func (rec recurrence) String() string {
+	s := "FREQ=" + rec.freq
+	if rec.interval != 1 {
+		s += ";INTERVAL=" + strconv.Itoa(rec.interval)
+	}
+	if len(rec.byDay) > 0 {
+		days := make([]string, len(rec.byDay))
+		for i, wd := range rec.byDay {
+			days[i] = strings.ToUpper(wd.String()[:2])
+		}
+		s += ";BYDAY=" + strings.Join(days, ",")
+	}
+	if rec.fromCompletion {
+		s += ";X-FROM=COMPLETION"
+	}
+	if rec.tzid != "" {
+		s += ";X-TZID=" + rec.tzid
+	}
+	return s
+}
+
+// next returns the due date of the occurrence following one due at due
+// (nil if the todo had no due date) that was completed or skipped at
+// done, in UTC. Occurrences that fell due before done are skipped over.
+func (rec recurrence) next(due *time.Time, done time.Time) time.Time {
+	done = done.In(rec.loc)
+	if rec.fromCompletion {
+		if due == nil {
+			return done.AddDate(0, 0, rec.interval).UTC()
+		}
+		// Keep the local time of day of the original due date.
+		d := due.In(rec.loc)
+		y, m, day := done.Date()
+		return time.Date(y, m, day+rec.interval, d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), rec.loc).UTC()
+	}
+
+	t := done
+	if due != nil {
+		t = due.In(rec.loc)
+	}
+	for i := 0; i < maxRecurrenceSteps; i++ {
+		t = rec.step(t)
+		if t.After(done) {
+			break
+		}
+	}
+	return t.UTC()
+}
+
+// step returns the occurrence after t in the rule's schedule, counting
+// days in t's location.
+func (rec recurrence) step(t time.Time) time.Time {
+	switch rec.freq {
+	case FreqWeekly:
+		if len(rec.byDay) == 0 {
+			return t.AddDate(0, 0, 7*rec.interval)
+		}
+		wd := weekdayIndex(t.Weekday())
+		for _, day := range rec.byDay {
+			if i := weekdayIndex(day); i > wd {
+				return t.AddDate(0, 0, i-wd)
+			}
+		}
+		// First listed day of the next week in the schedule.
+		return t.AddDate(0, 0, 7*rec.interval-wd+weekdayIndex(rec.byDay[0]))
+	case FreqMonthly:
+		// Months without this day of the month are skipped, as in RFC 5545.
+		y, m, d := t.Date()
+		for k := 1; k < maxRecurrenceSteps; k++ {
+			c := time.Date(y, m+time.Month(k*rec.interval), d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+			if c.Day() == d {
+				return c
+			}
+		}
+		return t.AddDate(0, rec.interval, 0)
+	default:
+		return t.AddDate(0, 0, rec.interval)
+	}
+}
+
+// weekdayIndex numbers weekdays from Monday (0) to Sunday (6).
+func weekdayIndex(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+// nextOccurrence returns the todo that follows t, a recurring todo that
+// was completed at done. It keeps t's title, priority, list, parent and
+// rule, and a reminder at the same offset before the new due date.
+func (t Todo) nextOccurrence(done time.Time) (Todo, error) {
+	rec, err := parseRecurrence(t.Recurrence)
+	if err != nil {
+		return Todo{}, err
+	}
+	due := rec.next(t.DueAt, done)
+	next := Todo{
+		OwnerID:    t.OwnerID,
+		Title:      t.Title,
+		DueAt:      &due,
+		Priority:   t.Priority,
+		ListID:     copyInt(t.ListID),
+		ParentID:   copyInt(t.ParentID),
+		Recurrence: t.Recurrence,
+	}
+	if t.RemindAt != nil && t.DueAt != nil {
+		remind := due.Add(t.RemindAt.Sub(*t.DueAt))
+		next.RemindAt = &remind
+	}
+	return next, nil
+}
@@ -0,0 +1,91 @@
+package todo
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// reminderBatchSize caps the reminders claimed per scheduler tick.
+const reminderBatchSize = 100
+
+// Reminder is fired when a todo's remind_at time arrives.
+type Reminder struct {
+	Todo    Todo
+	FiredAt time.Time
+}
+
+// Notifier delivers reminders. Implementations must be safe for
+// concurrent use.
+type Notifier interface {
+	Notify(ctx context.Context, r Reminder) error
+}
+
+// LogNotifier writes reminders to the standard logger. It is the default
+// Notifier.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, r Reminder) error {
+	due := "no due date"
+	if r.Todo.DueAt != nil {
+		due = "due " + r.Todo.DueAt.Format(time.RFC3339)
+	}
+	log.Printf("Reminder: todo %d %q (%s)", r.Todo.ID, r.Todo.Title, due)
+	return nil
+}
+
+// ReminderScheduler periodically claims due reminders from the repository
+// and hands them to a Notifier.
+type ReminderScheduler struct {
+	repo     Repository
+	notifier Notifier
+	interval time.Duration
+}
+
+// NewReminderScheduler creates a scheduler that polls every interval. A
+// nil notifier means LogNotifier.
+func NewReminderScheduler(repo Repository, notifier Notifier, interval time.Duration) *ReminderScheduler {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &ReminderScheduler{repo: repo, notifier: notifier, interval: interval}
+}
+
+// Run fires reminders until ctx is cancelled.
+func (s *ReminderScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.fire(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fire delivers every reminder that is currently due. A reminder is
+// claimed before it is delivered, so a failed delivery is logged and not
+// retried.
+func (s *ReminderScheduler) fire(ctx context.Context) {
+	for {
+		now := time.Now()
+		todos, err := s.repo.ClaimReminders(ctx, now, reminderBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to claim reminders: %v", err)
+			}
+			return
+		}
+		for _, t := range todos {
+			if err := s.notifier.Notify(ctx, Reminder{Todo: t, FiredAt: now}); err != nil {
+				log.Printf("Failed to send reminder for todo %d: %v", t.ID, err)
+			}
+		}
+		if len(todos) < reminderBatchSize {
+			return
+		}
+	}
+}
@@ -1,13 +1,112 @@
 package todo
 
-import "time"
+import (
+	"context"
+	"time"
+)
+
+// Repository stores todos and the lists and tags they are organized by.
+// Apart from the user methods and ClaimReminders, every method acts on
+// behalf of the user in ctx (see ContextWithUser) and fails with
+// ErrUnauthorized if there is none. Data the user cannot see is reported
+// as ErrNotFound: a user sees the todos in their inbox, their own tags
+// and the lists they are a member of, with those lists' todos.
+//
+// The repository only enforces visibility. Whether a member's role
+// allows a change is checked by Service.
 type Repository interface {
-	List() ([]Todo, error)
-	Create(title string) (Todo, error)
-	Get(id int) (Todo, error)
-	Update(id int, title string, completed bool, completedAt *time.Time) (Todo, error)
-	Delete(id int) error
-	Toggle(id int) (Todo, error)
-	Ping() error
-	CreateTableIfNotExists() error
+	List(ctx context.Context, opts ListOptions) (Page, error)
+	// Create stores a new todo. It is created now and open unless
+	// t.CreatedAt, t.Completed and t.CompletedAt say otherwise.
+	Create(ctx context.Context, t Todo) (Todo, error)
+	Get(ctx context.Context, id int) (Todo, error)
+	// Update, Delete and Toggle change a todo only if it is at the
+	// expected version, and fail with ErrPreconditionFailed otherwise.
+	// Update expects t.Version; Delete and Toggle accept any version if
+	// version is zero. Every change to a todo's own fields increments
+	// its version.
+	Update(ctx context.Context, t Todo) (Todo, error)
+	// Delete moves a todo and its subtasks to the trash. Apart from
+	// GetTrashed, Restore and List with ListOptions.Trashed, methods
+	// treat trashed todos as missing. Delete, Restore and a cascading
+	// Toggle leave alone the subtasks the user may not edit, and theirs.
+	Delete(ctx context.Context, id int, version int64) error
+	GetTrashed(ctx context.Context, id int) (Todo, error)
+	// Restore takes a todo out of the trash with the subtasks deleted
+	// along with it.
+	Restore(ctx context.Context, id int) (Todo, error)
+	// PurgeTrash permanently deletes every user's todos that were
+	// trashed before cutoff and returns how many were deleted.
+	PurgeTrash(ctx context.Context, cutoff time.Time) (int, error)
+	// Toggle flips the completion state of a todo. If cascade is set and
+	// the todo becomes completed, its open descendants are completed too.
+	Toggle(ctx context.Context, id int, version int64, cascade bool) (Todo, error)
+	// Ancestors returns the ids of a todo's parent, grandparent and so on,
+	// nearest first.
+	Ancestors(ctx context.Context, id int) ([]int, error)
+	Move(ctx context.Context, id, anchorID int, after bool) (Todo, error)
+	ClaimReminders(ctx context.Context, now time.Time, limit int) ([]Todo, error)
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// ListEvents returns audit log entries readable by the user: those
+	// for changes they made, and for todos, lists and tags they had
+	// access to when the change was made or have access to now. Every
+	// method that changes a todo, list or tag records the entries in the
+	// same transaction as the change.
+	ListEvents(ctx context.Context, f EventFilter) (EventPage, error)
+	// EventsAfter returns up to limit of the audit log entries readable by
+	// the user with an id greater than after, oldest first.
+	EventsAfter(ctx context.Context, after int64, limit int) ([]Event, error)
+	// LatestEventID returns the id of the latest audit log entry, readable
+	// by the user or not, or 0 if there is none.
+	LatestEventID(ctx context.Context) (int64, error)
+
+	ListTags(ctx context.Context) ([]Tag, error)
+	CreateTag(ctx context.Context, name string) (Tag, error)
+	RenameTag(ctx context.Context, id int, name string) (Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+	AttachTag(ctx context.Context, todoID, tagID int) (Todo, error)
+	DetachTag(ctx context.Context, todoID, tagID int) (Todo, error)
+
+	ListLists(ctx context.Context) ([]TodoList, error)
+	CreateList(ctx context.Context, name string) (TodoList, error)
+	GetList(ctx context.Context, id int) (TodoList, error)
+	RenameList(ctx context.Context, id int, name string) (TodoList, error)
+	// DeleteList removes a list, moving its todos to the trash if
+	// deleteTodos is set and to the inbox otherwise.
+	DeleteList(ctx context.Context, id int, deleteTodos bool) error
+
+	// ListRole returns the user's role in a list.
+	ListRole(ctx context.Context, listID int) (Role, error)
+	ListMembers(ctx context.Context, listID int) ([]Member, error)
+	// SetMemberRole and RemoveMember fail with ErrConflict if they would
+	// leave the list without an owner.
+	SetMemberRole(ctx context.Context, listID, userID int, role Role) (Member, error)
+	RemoveMember(ctx context.Context, listID, userID int) error
+	// CreateInvitation fails with ErrConflict if the email already
+	// belongs to a member or has a pending invitation to the list.
+	CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error)
+	// ListInvitations returns the invitations addressed to the user.
+	ListInvitations(ctx context.Context) ([]Invitation, error)
+	// ListSentInvitations returns the pending invitations to a list.
+	ListSentInvitations(ctx context.Context, listID int) ([]Invitation, error)
+	// GetInvitation returns an invitation addressed to the user or to a
+	// list the user is a member of.
+	GetInvitation(ctx context.Context, id int) (Invitation, error)
+	// AcceptInvitation makes the user a member of the list with the
+	// invited role and removes the invitation.
+	AcceptInvitation(ctx context.Context, id int) (Member, error)
+	DeleteInvitation(ctx context.Context, id int) error
+
+	CreateUser(ctx context.Context, email, passwordHash string) (User, error)
+	UserByEmail(ctx context.Context, email string) (User, error)
+	UserByID(ctx context.Context, id int) (User, error)
+
+	// WithinTx runs fn in a transaction: the methods fn calls with the
+	// context it is given see each other's changes, and those changes
+	// are committed together if fn returns nil and rolled back
+	// otherwise. Called inside another WithinTx, it rolls back only the
+	// changes made by fn.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,821 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is a Repository that keeps todos in process memory.
+// It is intended for local development and tests where no Postgres
+// instance is available. All methods are safe for concurrent use.
+type MemoryRepository struct {
+	mu sync.RWMutex
+	memoryData
+}
+
+// memoryData is the state of a MemoryRepository, kept apart so that
+// WithinTx can take a copy of it and roll back to it.
+type memoryData struct {
+	todos  map[int]Todo
+	nextID int
+
+	// reminded holds the ids of todos whose reminder has been claimed.
+	reminded map[int]bool
+
+	tags      map[int]Tag
+	nextTagID int
+	// todoTags maps a todo id to the set of its tag ids.
+	todoTags map[int]map[int]bool
+
+	lists      map[int]TodoList
+	nextListID int
+
+	// children maps a todo id to the set of its subtask ids.
+	children map[int]map[int]bool
+
+	users      map[int]User
+	nextUserID int
+
+	// members maps a list id to its members by user id.
+	members          map[int]map[int]Member
+	invitations      map[int]Invitation
+	nextInvitationID int
+
+	// events is the audit log, in id order.
+	events      []Event
+	nextEventID int64
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{memoryData: memoryData{
+		todos:      make(map[int]Todo),
+		nextID:     1,
+		reminded:   make(map[int]bool),
+		tags:       make(map[int]Tag),
+		nextTagID:  1,
+		todoTags:   make(map[int]map[int]bool),
+		lists:      make(map[int]TodoList),
+		nextListID: 1,
+		children:   make(map[int]map[int]bool),
+		users:      make(map[int]User),
+		nextUserID: 1,
+
+		members:          make(map[int]map[int]Member),
+		invitations:      make(map[int]Invitation),
+		nextInvitationID: 1,
+
+		nextEventID: 1,
+	}}
+}
+
+func (r *MemoryRepository) List(ctx context.Context, opts ListOptions) (Page, error) {
+	if err := ctx.Err(); err != nil {
+		return Page{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Page{}, err
+	}
+	cur, err := opts.cursor()
+	if err != nil {
+		return Page{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	var after *Todo
+	if cur != nil {
+		key, _ := cur.key()
+		after = &key
+	}
+
+	var todos []Todo
+	for _, t := range r.todos {
+		if !r.canAccess(owner, t) {
+			continue
+		}
+		t = r.view(t)
+		if !opts.matches(t) {
+			continue
+		}
+		if after != nil && opts.compare(t, *after) <= 0 {
+			continue
+		}
+		todos = append(todos, t)
+	}
+	slices.SortFunc(todos, opts.compare)
+	if len(todos) > opts.limit()+1 {
+		todos = todos[:opts.limit()+1]
+	}
+
+	return newPage(todos, opts), nil
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, t Todo) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	createdAt := t.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	// IDs are never reused, like a SERIAL column.
+	t = Todo{
+		ID:          r.nextID,
+		OwnerID:     owner,
+		Title:       t.Title,
+		Completed:   t.Completed,
+		CreatedAt:   createdAt,
+		CompletedAt: copyTime(t.CompletedAt),
+		DueAt:       copyTime(t.DueAt),
+		RemindAt:    copyTime(t.RemindAt),
+		Priority:    t.Priority,
+		ListID:      copyInt(t.ListID),
+		ParentID:    copyInt(t.ParentID),
+		Recurrence:  t.Recurrence,
+		Version:     1,
+	}
+	t.Position = r.maxPosition(scopeOf(t)) + positionGap
+	r.nextID++
+	r.todos[t.ID] = t
+	r.linkParent(t.ID, nil, t.ParentID)
+
+	created := r.view(t)
+	r.record(ctx, owner, EventCreate, nil, created)
+	return created, nil
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, id int) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	t, ok := r.visible(owner, id)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	return r.view(t), nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, u Todo) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, u.ID)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	if t.Version != u.Version {
+		return Todo{}, staleVersion(t.ID, u.Version)
+	}
+	before := r.view(t)
+	if !timeEqual(t.RemindAt, u.RemindAt) {
+		delete(r.reminded, t.ID)
+	}
+	wasCompleted := t.Completed
+	t.Title = u.Title
+	t.Completed = u.Completed
+	t.CompletedAt = copyTime(u.CompletedAt)
+	t.DueAt = copyTime(u.DueAt)
+	t.RemindAt = copyTime(u.RemindAt)
+	t.Priority = u.Priority
+	if scope := scopeOf(Todo{OwnerID: t.OwnerID, ListID: u.ListID}); scope != scopeOf(t) {
+		t.Position = r.maxPosition(scope) + positionGap
+	}
+	t.ListID = copyInt(u.ListID)
+	r.linkParent(t.ID, t.ParentID, u.ParentID)
+	t.ParentID = copyInt(u.ParentID)
+	t.Recurrence = u.Recurrence
+	t.Version++
+	r.todos[t.ID] = t
+
+	if !wasCompleted && t.Completed && t.Recurrence != "" {
+		if t, err = r.spawnNext(ctx, owner, t); err != nil {
+			return Todo{}, err
+		}
+	}
+
+	updated := r.view(t)
+	r.record(ctx, owner, EventUpdate, &before, updated)
+	return updated, nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id int, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, id)
+	if !ok {
+		return ErrNotFound
+	}
+	if version != 0 && t.Version != version {
+		return staleVersion(id, version)
+	}
+	r.trashTree(ctx, owner, id, time.Now())
+	return nil
+}
+
+// trashTree moves a todo and the descendants actor may edit that are not
+// already in the trash to the trash at now, recording the change for
+// actor parent first and subtasks in id order. r.mu must be held.
+func (r *MemoryRepository) trashTree(ctx context.Context, actor, id int, now time.Time) {
+	t := r.todos[id]
+	before := r.view(t)
+	t.DeletedAt = copyTime(&now)
+	t.Version++
+	r.todos[id] = t
+	r.record(ctx, actor, EventDelete, &before, r.view(t))
+	for _, childID := range slices.Sorted(maps.Keys(r.children[id])) {
+		if c := r.todos[childID]; c.DeletedAt == nil && r.canEdit(actor, c) {
+			r.trashTree(ctx, actor, childID, now)
+		}
+	}
+}
+
+func (r *MemoryRepository) GetTrashed(ctx context.Context, id int) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	t, ok := r.todos[id]
+	if !ok || t.DeletedAt == nil || !r.canAccess(owner, t) {
+		return Todo{}, ErrNotFound
+	}
+	return r.view(t), nil
+}
+
+func (r *MemoryRepository) Restore(ctx context.Context, id int) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.todos[id]
+	if !ok || t.DeletedAt == nil || !r.canAccess(owner, t) {
+		return Todo{}, ErrNotFound
+	}
+	if t.ParentID != nil && r.todos[*t.ParentID].DeletedAt != nil {
+		return Todo{}, fmt.Errorf("%w: the parent of todo %d is in the trash; restore it first", ErrConflict, id)
+	}
+	r.restoreTree(ctx, owner, id, *t.DeletedAt)
+
+	return r.view(r.todos[id]), nil
+}
+
+// restoreTree takes a todo and the descendants actor may edit that were
+// trashed at the same time as it out of the trash, recording the change
+// for actor in the order of trashTree. r.mu must be held.
+func (r *MemoryRepository) restoreTree(ctx context.Context, actor, id int, deletedAt time.Time) {
+	t := r.todos[id]
+	before := r.view(t)
+	t.DeletedAt = nil
+	t.Version++
+	r.todos[id] = t
+	r.record(ctx, actor, EventRestore, &before, r.view(t))
+	for _, childID := range slices.Sorted(maps.Keys(r.children[id])) {
+		if c := r.todos[childID]; c.DeletedAt != nil && c.DeletedAt.Equal(deletedAt) && r.canEdit(actor, c) {
+			r.restoreTree(ctx, actor, childID, deletedAt)
+		}
+	}
+}
+
+func (r *MemoryRepository) PurgeTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	defer r.lock(ctx)()
+
+	before := len(r.todos)
+	for _, id := range slices.Sorted(maps.Keys(r.todos)) {
+		if t, ok := r.todos[id]; ok && t.DeletedAt != nil && t.DeletedAt.Before(cutoff) {
+			r.deleteTree(ctx, id)
+		}
+	}
+	return before - len(r.todos), nil
+}
+
+// deleteTree removes a todo and all of its descendants, like the
+// parent_id foreign key does in Postgres, recording their purge on
+// behalf of the server. r.mu must be held.
+func (r *MemoryRepository) deleteTree(ctx context.Context, id int) {
+	t, ok := r.todos[id]
+	if !ok {
+		return
+	}
+	for _, childID := range slices.Sorted(maps.Keys(r.children[id])) {
+		r.deleteTree(ctx, childID)
+	}
+	purged := r.view(t)
+	r.appendEvent(ctx, newEvent(0, EventPurge, &purged, nil))
+	r.linkParent(id, t.ParentID, nil)
+	delete(r.children, id)
+	delete(r.todos, id)
+	delete(r.reminded, id)
+	delete(r.todoTags, id)
+}
+
+func (r *MemoryRepository) Toggle(ctx context.Context, id int, version int64, cascade bool) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, id)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	if version != 0 && t.Version != version {
+		return Todo{}, staleVersion(id, version)
+	}
+	before := r.view(t)
+	t.Completed = !t.Completed
+	t.Version++
+	if t.Completed {
+		now := time.Now()
+		t.CompletedAt = &now
+	} else {
+		t.CompletedAt = nil
+	}
+	r.todos[id] = t
+
+	if cascade && t.Completed {
+		if err := r.completeDescendants(ctx, owner, id, *t.CompletedAt); err != nil {
+			return Todo{}, err
+		}
+	}
+	if t.Completed && t.Recurrence != "" {
+		if t, err = r.spawnNext(ctx, owner, t); err != nil {
+			return Todo{}, err
+		}
+	}
+
+	toggled := r.view(t)
+	r.record(ctx, owner, EventToggle, &before, toggled)
+	return toggled, nil
+}
+
+// spawnNext creates the next occurrence of the recurring todo t, which
+// was just completed by actor, and hands the recurrence rule over to it.
+// It returns t as updated. r.mu must be held.
+func (r *MemoryRepository) spawnNext(ctx context.Context, actor int, t Todo) (Todo, error) {
+	done := time.Now()
+	if t.CompletedAt != nil {
+		done = *t.CompletedAt
+	}
+	next, err := t.nextOccurrence(done)
+	if err != nil {
+		return Todo{}, err
+	}
+	next.ID = r.nextID
+	next.CreatedAt = time.Now()
+	next.Position = r.maxPosition(scopeOf(next)) + positionGap
+	next.Version = 1
+	r.nextID++
+	r.todos[next.ID] = next
+	r.linkParent(next.ID, nil, next.ParentID)
+	for tagID := range r.todoTags[t.ID] {
+		if r.todoTags[next.ID] == nil {
+			r.todoTags[next.ID] = make(map[int]bool)
+		}
+		r.todoTags[next.ID][tagID] = true
+	}
+	r.record(ctx, actor, EventCreate, nil, r.view(next))
+
+	t.Recurrence = ""
+	t.Version++
+	r.todos[t.ID] = t
+	return t, nil
+}
+
+// completeDescendants completes every open descendant of todo id that
+// actor may edit, recording the change for actor, and spawns the next
+// occurrence of those that recur. r.mu must be held.
+func (r *MemoryRepository) completeDescendants(ctx context.Context, actor, id int, now time.Time) error {
+	// The ids are collected first: a spawned occurrence becomes a
+	// sibling, and must not be completed in turn.
+	for _, childID := range slices.Sorted(maps.Keys(r.children[id])) {
+		c := r.todos[childID]
+		if c.DeletedAt != nil || !r.canEdit(actor, c) {
+			continue
+		}
+		if !c.Completed {
+			before := r.view(c)
+			c.Completed = true
+			c.CompletedAt = copyTime(&now)
+			c.Version++
+			r.todos[childID] = c
+			if c.Recurrence != "" {
+				var err error
+				if c, err = r.spawnNext(ctx, actor, c); err != nil {
+					return err
+				}
+			}
+			r.record(ctx, actor, EventToggle, &before, r.view(c))
+		}
+		if err := r.completeDescendants(ctx, actor, childID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) Ancestors(ctx context.Context, id int) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	t, ok := r.visible(owner, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var ids []int
+	for t.ParentID != nil && len(ids) < maxDepth {
+		ids = append(ids, *t.ParentID)
+		t = r.todos[*t.ParentID]
+	}
+	return ids, nil
+}
+
+// linkParent moves todo id from the children of oldParent to those of
+// newParent. r.mu must be held.
+func (r *MemoryRepository) linkParent(id int, oldParent, newParent *int) {
+	if oldParent != nil {
+		delete(r.children[*oldParent], id)
+	}
+	if newParent != nil {
+		if r.children[*newParent] == nil {
+			r.children[*newParent] = make(map[int]bool)
+		}
+		r.children[*newParent][id] = true
+	}
+}
+
+// Search approximates the Postgres full-text search with prefix word
+// matching; ranks are not comparable with PostgresRepository's.
+func (r *MemoryRepository) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	terms := splitWords(query)
+
+	defer r.rlock(ctx)()
+
+	results := []SearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+	for _, t := range r.todos {
+		if !r.canSee(owner, t) {
+			continue
+		}
+		if rank, snippet, ok := matchText(t.Title, terms); ok {
+			results = append(results, SearchResult{Todo: r.view(t), Rank: rank, Snippet: snippet})
+		}
+	}
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	if n := searchLimit(limit); len(results) > n {
+		results = results[:n]
+	}
+
+	return results, nil
+}
+
+func (r *MemoryRepository) Move(ctx context.Context, id, anchorID int, after bool) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, id)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	anchor, ok := r.visible(owner, anchorID)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	scope := scopeOf(anchor)
+	if scope != scopeOf(t) {
+		return Todo{}, errOtherScope(id, anchorID)
+	}
+	pos, err := r.movePosition(scope, id, anchor, after)
+	if errors.Is(err, errNoGap) {
+		r.renumber(scope)
+		pos, err = r.movePosition(scope, id, r.todos[anchorID], after)
+	}
+	if err != nil {
+		return Todo{}, err
+	}
+	before := r.view(t)
+	t = r.todos[id]
+	t.Position = pos
+	t.Version++
+	r.todos[id] = t
+
+	moved := r.view(t)
+	r.record(ctx, owner, EventMove, &before, moved)
+	return moved, nil
+}
+
+// movePosition mirrors the Postgres neighbour lookup. r.mu must be held.
+func (r *MemoryRepository) movePosition(scope positionScope, id int, anchor Todo, after bool) (int64, error) {
+	byPosition := ListOptions{Sort: SortByPosition}
+
+	var neighbour *Todo
+	for _, t := range r.todos {
+		if t.ID == id || t.DeletedAt != nil || !scope.contains(t) {
+			continue
+		}
+		c := byPosition.compare(t, anchor)
+		if after && c > 0 && (neighbour == nil || byPosition.compare(t, *neighbour) < 0) ||
+			!after && c < 0 && (neighbour == nil || byPosition.compare(t, *neighbour) > 0) {
+			n := t
+			neighbour = &n
+		}
+	}
+	if neighbour == nil {
+		return endPosition(anchor.Position, after), nil
+	}
+	return midPosition(anchor.Position, neighbour.Position)
+}
+
+// renumber spreads the positions in scope positionGap apart. r.mu must be
+// held.
+func (r *MemoryRepository) renumber(scope positionScope) {
+	var todos []Todo
+	for _, t := range r.todos {
+		if scope.contains(t) {
+			todos = append(todos, t)
+		}
+	}
+	slices.SortFunc(todos, ListOptions{Sort: SortByPosition}.compare)
+	for i, t := range todos {
+		t.Position = int64(i+1) * positionGap
+		r.todos[t.ID] = t
+	}
+}
+
+// maxPosition returns the largest position in scope, or 0. r.mu must be
+// held.
+func (r *MemoryRepository) maxPosition(scope positionScope) int64 {
+	var pos int64
+	for _, t := range r.todos {
+		if scope.contains(t) {
+			pos = max(pos, t.Position)
+		}
+	}
+	return pos
+}
+
+// canSee reports whether user can see t: it is not in the trash and
+// they have access to it. r.mu must be held.
+func (r *MemoryRepository) canSee(user int, t Todo) bool {
+	return t.DeletedAt == nil && r.canAccess(user, t)
+}
+
+// canAccess reports whether t is in user's inbox or in a list they are a
+// member of. r.mu must be held.
+func (r *MemoryRepository) canAccess(user int, t Todo) bool {
+	if t.ListID == nil {
+		return t.OwnerID == user
+	}
+	_, ok := r.members[*t.ListID][user]
+	return ok
+}
+
+// canEdit is canAccess without the todos of lists where user is only a
+// viewer, mirroring editableBy. r.mu must be held.
+func (r *MemoryRepository) canEdit(user int, t Todo) bool {
+	if t.ListID == nil {
+		return t.OwnerID == user
+	}
+	m, ok := r.members[*t.ListID][user]
+	return ok && m.Role.allows(RoleEditor)
+}
+
+// visible returns todo id if user can see it. r.mu must be held.
+func (r *MemoryRepository) visible(user, id int) (Todo, bool) {
+	t, ok := r.todos[id]
+	return t, ok && r.canSee(user, t)
+}
+
+func (r *MemoryRepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	defer r.lock(ctx)()
+
+	var due []Todo
+	for _, t := range r.todos {
+		if t.RemindAt != nil && !t.RemindAt.After(now) && !t.Completed && t.DeletedAt == nil && !r.reminded[t.ID] {
+			due = append(due, t)
+		}
+	}
+	slices.SortFunc(due, func(a, b Todo) int { return a.RemindAt.Compare(*b.RemindAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i, t := range due {
+		r.reminded[t.ID] = true
+		due[i] = r.view(t)
+	}
+
+	return due, nil
+}
+
+// memoryTxKey is the context key under which WithinTx marks the
+// transaction's context with the repository it holds the lock of.
+type memoryTxKey struct{}
+
+// WithinTx runs fn holding the repository's lock, so that the methods it
+// calls with the context it is given see no concurrent changes, and
+// rolls every change back if fn fails. Nested calls roll back only their
+// own changes, like savepoints. As with Postgres sequences, the ids handed
+// out by a rolled back call are not reused.
+func (r *MemoryRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !r.inTx(ctx) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		ctx = context.WithValue(ctx, memoryTxKey{}, r)
+	}
+
+	saved := r.memoryData.clone()
+	rollback := recorderFrom(ctx).mark()
+	if err := fn(ctx); err != nil {
+		saved.keepIDs(&r.memoryData)
+		r.memoryData = saved
+		rollback()
+		return err
+	}
+	return nil
+}
+
+func (r *MemoryRepository) inTx(ctx context.Context) bool {
+	tx, _ := ctx.Value(memoryTxKey{}).(*MemoryRepository)
+	return tx == r
+}
+
+// lock write-locks the repository for a method call and returns the
+// function that unlocks it. Inside WithinTx the lock is already held.
+func (r *MemoryRepository) lock(ctx context.Context) (unlock func()) {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// rlock is lock for methods that only read.
+func (r *MemoryRepository) rlock(ctx context.Context) (unlock func()) {
+	if r.inTx(ctx) {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+// clone returns a copy of d that shares nothing mutable with it. Todos,
+// tags and the other values are replaced rather than changed in place,
+// so copying the maps that hold them is enough.
+func (d *memoryData) clone() memoryData {
+	c := *d
+	c.todos = maps.Clone(d.todos)
+	c.reminded = maps.Clone(d.reminded)
+	c.tags = maps.Clone(d.tags)
+	c.todoTags = cloneSets(d.todoTags)
+	c.lists = maps.Clone(d.lists)
+	c.children = cloneSets(d.children)
+	c.users = maps.Clone(d.users)
+	c.members = make(map[int]map[int]Member, len(d.members))
+	for id, m := range d.members {
+		c.members[id] = maps.Clone(m)
+	}
+	c.invitations = maps.Clone(d.invitations)
+	// Events are only ever appended, so capping the slice at its length
+	// keeps later appends out of the copy.
+	c.events = d.events[:len(d.events):len(d.events)]
+	return c
+}
+
+// keepIDs carries the next ids of from over to d, so that rolling back
+// to d does not hand out the ids allocated since again.
+func (d *memoryData) keepIDs(from *memoryData) {
+	d.nextID = from.nextID
+	d.nextTagID = from.nextTagID
+	d.nextListID = from.nextListID
+	d.nextUserID = from.nextUserID
+	d.nextInvitationID = from.nextInvitationID
+	d.nextEventID = from.nextEventID
+}
+
+func cloneSets(m map[int]map[int]bool) map[int]map[int]bool {
+	c := make(map[int]map[int]bool, len(m))
+	for k, set := range m {
+		c[k] = maps.Clone(set)
+	}
+	return c
+}
+
+// Ping always succeeds for the in-memory store.
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func timeEqual(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// copyTime returns a pointer to a copy of *t so stored todos don't
+// alias caller-owned values.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// record appends an event by actor for a change of a todo from before,
+// nil for a new todo, to after. Both are views of the todo. r.mu must be
+// held.
+func (r *MemoryRepository) record(ctx context.Context, actor int, action EventAction, before *Todo, after Todo) {
+	r.appendEvent(ctx, newEvent(actor, action, before, &after))
+}
+
+// appendEvent appends e to the audit log and passes it to the recorder
+// in ctx. r.mu must be held.
+func (r *MemoryRepository) appendEvent(ctx context.Context, e Event) {
+	e.ID = r.nextEventID
+	e.CreatedAt = time.Now()
+	r.nextEventID++
+	r.events = append(r.events, e)
+	recorderFrom(ctx).add(e)
+}
+
+func (r *MemoryRepository) ListEvents(ctx context.Context, f EventFilter) (EventPage, error) {
+	if err := ctx.Err(); err != nil {
+		return EventPage{}, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return EventPage{}, err
+	}
+	cursor, err := f.cursor()
+	if err != nil {
+		return EventPage{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	// Events are appended in id order, so walk them backwards for the
+	// newest first.
+	var events []Event
+	for i := len(r.events) - 1; i >= 0 && len(events) <= f.limit(); i-- {
+		e := r.events[i]
+		if cursor != 0 && e.ID >= cursor {
+			continue
+		}
+		if f.matches(e) && r.canReadEvent(user, e) {
+			events = append(events, e)
+		}
+	}
+
+	return newEventPage(events, f), nil
+}
+
+func (r *MemoryRepository) EventsAfter(ctx context.Context, after int64, limit int) ([]Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	i, _ := slices.BinarySearchFunc(r.events, after+1, func(e Event, id int64) int {
+		return cmp.Compare(e.ID, id)
+	})
+	var events []Event
+	for _, e := range r.events[i:] {
+		if len(events) == limit {
+			break
+		}
+		if r.canReadEvent(user, e) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (r *MemoryRepository) LatestEventID(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	defer r.rlock(ctx)()
+	if len(r.events) == 0 {
+		return 0, nil
+	}
+	return r.events[len(r.events)-1].ID, nil
+}
+
+// canReadEvent reports whether user made the change of e, had access to
+// the todo when e was recorded, or has access to it now. r.mu must be
+// held.
+func (r *MemoryRepository) canReadEvent(user int, e Event) bool {
+	if e.ActorID == user || r.canAccess(user, Todo{OwnerID: e.ownerID, ListID: e.ListID}) {
+		return true
+	}
+	t, ok := r.todos[e.TodoID]
+	return ok && r.canAccess(user, t)
+}
@@ -0,0 +1,171 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+func (r *MemoryRepository) ListLists(ctx context.Context) ([]TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	lists := []TodoList{}
+	for id := range r.members {
+		if _, ok := r.members[id][owner]; ok {
+			lists = append(lists, r.listView(r.lists[id], owner))
+		}
+	}
+	slices.SortFunc(lists, func(a, b TodoList) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return lists, nil
+}
+
+func (r *MemoryRepository) CreateList(ctx context.Context, name string) (TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return TodoList{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	l := TodoList{ID: r.nextListID, OwnerID: owner, Name: name, CreatedAt: time.Now()}
+	r.nextListID++
+	r.lists[l.ID] = l
+	// The creator is the list's first owner.
+	r.members[l.ID] = map[int]Member{
+		owner: {ListID: l.ID, UserID: owner, Role: RoleOwner, JoinedAt: l.CreatedAt},
+	}
+
+	r.appendEvent(ctx, newListEvent(owner, EventListCreate, l))
+	return r.listView(l, owner), nil
+}
+
+func (r *MemoryRepository) GetList(ctx context.Context, id int) (TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return TodoList{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	l, ok := r.memberList(owner, id)
+	if !ok {
+		return TodoList{}, ErrNotFound
+	}
+	return r.listView(l, owner), nil
+}
+
+func (r *MemoryRepository) RenameList(ctx context.Context, id int, name string) (TodoList, error) {
+	if err := ctx.Err(); err != nil {
+		return TodoList{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	l, ok := r.memberList(owner, id)
+	if !ok {
+		return TodoList{}, ErrNotFound
+	}
+	l.Name = name
+	r.lists[id] = l
+
+	r.appendEvent(ctx, newListEvent(owner, EventListUpdate, l))
+	return r.listView(l, owner), nil
+}
+
+func (r *MemoryRepository) DeleteList(ctx context.Context, id int, deleteTodos bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.lock(ctx)()
+
+	l, ok := r.memberList(owner, id)
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.lists, id)
+	delete(r.members, id)
+	for invID, inv := range r.invitations {
+		if inv.ListID == id {
+			delete(r.invitations, invID)
+		}
+	}
+	var inList []int
+	for _, t := range r.todos {
+		if t.ListID != nil && *t.ListID == id {
+			inList = append(inList, t.ID)
+		}
+	}
+	slices.Sort(inList)
+	now := time.Now()
+	for _, todoID := range inList {
+		if deleteTodos && r.todos[todoID].DeletedAt == nil {
+			// Subtasks go too, wherever they are filed.
+			r.trashTree(ctx, owner, todoID, now)
+		}
+		t := r.todos[todoID]
+		before := r.view(t)
+		t.ListID = nil
+		t.Version++
+		r.todos[todoID] = t
+		r.record(ctx, owner, EventUpdate, &before, r.view(t))
+	}
+	r.appendEvent(ctx, newListEvent(owner, EventListDelete, l))
+	return nil
+}
+
+// memberList returns list id if user is a member of it. r.mu must be
+// held.
+func (r *MemoryRepository) memberList(user, id int) (TodoList, bool) {
+	l, ok := r.lists[id]
+	_, member := r.members[id][user]
+	return l, ok && member
+}
+
+// listView returns l with user's role and its todo counts filled in. r.mu
+// must be held.
+func (r *MemoryRepository) listView(l TodoList, user int) TodoList {
+	l.Role = r.members[l.ID][user].Role
+	l.OpenCount, l.CompletedCount = 0, 0
+	for _, t := range r.todos {
+		if t.ListID == nil || *t.ListID != l.ID || t.DeletedAt != nil {
+			continue
+		}
+		if t.Completed {
+			l.CompletedCount++
+		} else {
+			l.OpenCount++
+		}
+	}
+	return l
+}
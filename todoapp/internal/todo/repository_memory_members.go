@@ -0,0 +1,301 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+func (r *MemoryRepository) ListRole(ctx context.Context, listID int) (Role, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer r.rlock(ctx)()
+
+	m, ok := r.members[listID][user]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return m.Role, nil
+}
+
+func (r *MemoryRepository) ListMembers(ctx context.Context, listID int) ([]Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	if _, ok := r.memberList(user, listID); !ok {
+		return nil, ErrNotFound
+	}
+	members := []Member{}
+	for _, m := range r.members[listID] {
+		members = append(members, r.memberView(m))
+	}
+	slices.SortFunc(members, func(a, b Member) int {
+		if c := a.JoinedAt.Compare(b.JoinedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+
+	return members, nil
+}
+
+func (r *MemoryRepository) SetMemberRole(ctx context.Context, listID, userID int, role Role) (Member, error) {
+	if err := ctx.Err(); err != nil {
+		return Member{}, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Member{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.memberList(user, listID); !ok {
+		return Member{}, ErrNotFound
+	}
+	m, ok := r.members[listID][userID]
+	if !ok {
+		return Member{}, ErrNotFound
+	}
+	if role != RoleOwner {
+		if err := r.checkLastOwner(listID, userID); err != nil {
+			return Member{}, err
+		}
+	}
+	m.Role = role
+	r.members[listID][userID] = m
+
+	return r.memberView(m), nil
+}
+
+func (r *MemoryRepository) RemoveMember(ctx context.Context, listID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.memberList(user, listID); !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.members[listID][userID]; !ok {
+		return ErrNotFound
+	}
+	if err := r.checkLastOwner(listID, userID); err != nil {
+		return err
+	}
+	delete(r.members[listID], userID)
+	return nil
+}
+
+// checkLastOwner fails with ErrConflict if userID is the list's only
+// owner. r.mu must be held.
+func (r *MemoryRepository) checkLastOwner(listID, userID int) error {
+	for _, m := range r.members[listID] {
+		if m.Role == RoleOwner && m.UserID != userID {
+			return nil
+		}
+	}
+	if r.members[listID][userID].Role != RoleOwner {
+		return nil
+	}
+	return fmt.Errorf("%w: list %d must keep at least one owner", ErrConflict, listID)
+}
+
+func (r *MemoryRepository) CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return Invitation{}, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	l, ok := r.memberList(user, inv.ListID)
+	if !ok {
+		return Invitation{}, ErrNotFound
+	}
+	for _, m := range r.members[inv.ListID] {
+		if r.users[m.UserID].Email == inv.Email {
+			return Invitation{}, fmt.Errorf("%w: %s is already a member of list %d", ErrConflict, inv.Email, inv.ListID)
+		}
+	}
+	for _, other := range r.invitations {
+		if other.ListID == inv.ListID && other.Email == inv.Email {
+			return Invitation{}, fmt.Errorf("%w: %s has already been invited to list %d", ErrConflict, inv.Email, inv.ListID)
+		}
+	}
+	inv = Invitation{
+		ID:        r.nextInvitationID,
+		ListID:    l.ID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		InvitedBy: user,
+		CreatedAt: time.Now(),
+	}
+	r.nextInvitationID++
+	r.invitations[inv.ID] = inv
+
+	return r.invitationView(inv), nil
+}
+
+func (r *MemoryRepository) ListInvitations(ctx context.Context) ([]Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	email := r.users[user].Email
+	return r.collectInvitations(func(inv Invitation) bool { return inv.Email == email }), nil
+}
+
+func (r *MemoryRepository) ListSentInvitations(ctx context.Context, listID int) ([]Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	if _, ok := r.memberList(user, listID); !ok {
+		return []Invitation{}, nil
+	}
+	return r.collectInvitations(func(inv Invitation) bool { return inv.ListID == listID }), nil
+}
+
+// collectInvitations returns the invitations matching keep, oldest
+// first. r.mu must be held.
+func (r *MemoryRepository) collectInvitations(keep func(Invitation) bool) []Invitation {
+	invitations := []Invitation{}
+	for _, inv := range r.invitations {
+		if keep(inv) {
+			invitations = append(invitations, r.invitationView(inv))
+		}
+	}
+	slices.SortFunc(invitations, func(a, b Invitation) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return invitations
+}
+
+func (r *MemoryRepository) GetInvitation(ctx context.Context, id int) (Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return Invitation{}, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	inv, ok := r.visibleInvitation(user, id)
+	if !ok {
+		return Invitation{}, ErrNotFound
+	}
+	return r.invitationView(inv), nil
+}
+
+func (r *MemoryRepository) AcceptInvitation(ctx context.Context, id int) (Member, error) {
+	if err := ctx.Err(); err != nil {
+		return Member{}, err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Member{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	inv, ok := r.invitations[id]
+	if !ok || inv.Email != r.users[user].Email {
+		return Member{}, ErrNotFound
+	}
+	delete(r.invitations, id)
+	// The user may have been made a member since they were invited; keep
+	// the role they have.
+	m, ok := r.members[inv.ListID][user]
+	if !ok {
+		m = Member{ListID: inv.ListID, UserID: user, Role: inv.Role, JoinedAt: time.Now()}
+		r.members[inv.ListID][user] = m
+	}
+
+	return r.memberView(m), nil
+}
+
+func (r *MemoryRepository) DeleteInvitation(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.visibleInvitation(user, id); !ok {
+		return ErrNotFound
+	}
+	delete(r.invitations, id)
+	return nil
+}
+
+// visibleInvitation returns invitation id if it is addressed to user or
+// to a list user is a member of. r.mu must be held.
+func (r *MemoryRepository) visibleInvitation(user, id int) (Invitation, bool) {
+	inv, ok := r.invitations[id]
+	if !ok {
+		return Invitation{}, false
+	}
+	if _, member := r.members[inv.ListID][user]; member || inv.Email == r.users[user].Email {
+		return inv, true
+	}
+	return Invitation{}, false
+}
+
+// memberView returns m with the member's email filled in. r.mu must be
+// held.
+func (r *MemoryRepository) memberView(m Member) Member {
+	m.Email = r.users[m.UserID].Email
+	return m
+}
+
+// invitationView returns inv with its list name filled in. r.mu must be
+// held.
+func (r *MemoryRepository) invitationView(inv Invitation) Invitation {
+	inv.ListName = r.lists[inv.ListID].Name
+	return inv
+}
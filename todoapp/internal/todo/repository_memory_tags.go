@@ -0,0 +1,199 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+func (r *MemoryRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.rlock(ctx)()
+
+	tags := []Tag{}
+	for _, t := range r.tags {
+		if t.OwnerID == owner {
+			tags = append(tags, t)
+		}
+	}
+	slices.SortFunc(tags, compareTags)
+
+	return tags, nil
+}
+
+func (r *MemoryRepository) CreateTag(ctx context.Context, name string) (Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return Tag{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	if err := r.checkTagName(owner, 0, name); err != nil {
+		return Tag{}, err
+	}
+	t := Tag{ID: r.nextTagID, Name: name, OwnerID: owner}
+	r.nextTagID++
+	r.tags[t.ID] = t
+
+	r.appendEvent(ctx, newTagEvent(owner, EventTagCreate, t))
+	return t, nil
+}
+
+func (r *MemoryRepository) RenameTag(ctx context.Context, id int, name string) (Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return Tag{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.tags[id]
+	if !ok || t.OwnerID != owner {
+		return Tag{}, ErrNotFound
+	}
+	if err := r.checkTagName(owner, id, name); err != nil {
+		return Tag{}, err
+	}
+	t.Name = name
+	r.tags[id] = t
+
+	r.appendEvent(ctx, newTagEvent(owner, EventTagUpdate, t))
+	return t, nil
+}
+
+func (r *MemoryRepository) DeleteTag(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.tags[id]
+	if !ok || t.OwnerID != owner {
+		return ErrNotFound
+	}
+	delete(r.tags, id)
+	for _, tagIDs := range r.todoTags {
+		delete(tagIDs, id)
+	}
+	r.appendEvent(ctx, newTagEvent(owner, EventTagDelete, t))
+	return nil
+}
+
+func (r *MemoryRepository) AttachTag(ctx context.Context, todoID, tagID int) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, todoID)
+	if !ok {
+		return Todo{}, ErrNotFound
+	}
+	if tag, ok := r.tags[tagID]; !ok || tag.OwnerID != owner {
+		return Todo{}, ErrNotFound
+	}
+	if r.todoTags[todoID][tagID] {
+		return r.view(t), nil
+	}
+	before := r.view(t)
+	if r.todoTags[todoID] == nil {
+		r.todoTags[todoID] = make(map[int]bool)
+	}
+	r.todoTags[todoID][tagID] = true
+
+	tagged := r.view(t)
+	r.record(ctx, owner, EventUpdate, &before, tagged)
+	return tagged, nil
+}
+
+func (r *MemoryRepository) DetachTag(ctx context.Context, todoID, tagID int) (Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return Todo{}, err
+	}
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	t, ok := r.visible(owner, todoID)
+	if !ok || !r.todoTags[todoID][tagID] {
+		return Todo{}, ErrNotFound
+	}
+	before := r.view(t)
+	delete(r.todoTags[todoID], tagID)
+
+	untagged := r.view(t)
+	r.record(ctx, owner, EventUpdate, &before, untagged)
+	return untagged, nil
+}
+
+// checkTagName enforces case-insensitive uniqueness of the owner's tag
+// names, ignoring the tag being renamed. r.mu must be held.
+func (r *MemoryRepository) checkTagName(owner, id int, name string) error {
+	for _, t := range r.tags {
+		if t.OwnerID == owner && t.ID != id && strings.EqualFold(t.Name, name) {
+			return fmt.Errorf("%w: tag %q already exists", ErrConflict, t.Name)
+		}
+	}
+	return nil
+}
+
+// view returns t with its tags and subtask progress filled in. r.mu must
+// be held.
+func (r *MemoryRepository) view(t Todo) Todo {
+	t.Progress = nil
+	for childID := range r.children[t.ID] {
+		if r.todos[childID].DeletedAt != nil {
+			continue
+		}
+		if t.Progress == nil {
+			t.Progress = &Progress{}
+		}
+		t.Progress.Total++
+		if r.todos[childID].Completed {
+			t.Progress.Completed++
+		}
+	}
+
+	t.Tags = []Tag{}
+	for tagID := range r.todoTags[t.ID] {
+		t.Tags = append(t.Tags, r.tags[tagID])
+	}
+	slices.SortFunc(t.Tags, compareTags)
+	return t
+}
+
+func compareTags(a, b Tag) int {
+	if c := strings.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
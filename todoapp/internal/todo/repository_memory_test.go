@@ -0,0 +1,335 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newUser registers a user with repo and returns a context acting as them.
+func newUser(t *testing.T, repo Repository, email string) context.Context {
+	t.Helper()
+	u, err := repo.CreateUser(context.Background(), email, "hash")
+	if err != nil {
+		t.Fatalf("CreateUser(%q): %v", email, err)
+	}
+	return ContextWithUser(context.Background(), u.ID)
+}
+
+func TestMemoryRepositoryGet(t *testing.T) {
+	repo := NewMemoryRepository()
+	alice := newUser(t, repo, "alice@example.com")
+	bob := newUser(t, repo, "bob@example.com")
+	created, err := repo.Create(alice, Todo{Title: "Buy milk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		id      int
+		wantErr error
+	}{
+		{"owner", alice, created.ID, nil},
+		{"missing", alice, created.ID + 1, ErrNotFound},
+		{"other user", bob, created.ID, ErrNotFound},
+		{"no user", context.Background(), created.ID, ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Get(tt.ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get(%d) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			if err == nil && got.Title != "Buy milk" {
+				t.Errorf("Get(%d).Title = %q, want %q", tt.id, got.Title, "Buy milk")
+			}
+		})
+	}
+}
+
+func TestMemoryRepositoryCreate(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := newUser(t, repo, "alice@example.com")
+
+	first, err := repo.Create(ctx, Todo{Title: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	second, err := repo.Create(ctx, Todo{Title: "second", Completed: true, CompletedAt: &done})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Errorf("ids = %d, %d, want increasing from 1", first.ID, second.ID)
+	}
+	if first.CreatedAt.IsZero() {
+		t.Error("CreatedAt is not set")
+	}
+	if first.Version != 1 {
+		t.Errorf("Version = %d, want 1", first.Version)
+	}
+	if second.Position <= first.Position {
+		t.Errorf("positions = %d, %d, want appended in order", first.Position, second.Position)
+	}
+	list, err := repo.CreateList(ctx, "Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	inList, err := repo.Create(ctx, Todo{Title: "in a list", ListID: &list.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inList.Position != first.Position {
+		t.Errorf("first position in a list = %d, want %d as in the inbox", inList.Position, first.Position)
+	}
+	if first.CompletedAt != nil {
+		t.Error("open todo has CompletedAt set")
+	}
+	if second.CompletedAt == nil || !second.CompletedAt.Equal(done) {
+		t.Errorf("CompletedAt = %v, want %v", second.CompletedAt, done)
+	}
+}
+
+func TestMemoryRepositoryVersions(t *testing.T) {
+	tests := []struct {
+		name string
+		// change changes todo id, created at version 1, expecting version.
+		change  func(ctx context.Context, repo *MemoryRepository, id int, version int64) error
+		version int64
+		wantErr error
+	}{
+		{"update current", updateTitle, 1, nil},
+		{"update stale", updateTitle, 2, ErrPreconditionFailed},
+		{"toggle current", toggle, 1, nil},
+		{"toggle any", toggle, 0, nil},
+		{"toggle stale", toggle, 5, ErrPreconditionFailed},
+		{"delete current", remove, 1, nil},
+		{"delete any", remove, 0, nil},
+		{"delete stale", remove, 3, ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryRepository()
+			ctx := newUser(t, repo, "alice@example.com")
+			created, err := repo.Create(ctx, Todo{Title: "Buy milk"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.change(ctx, repo, created.ID, tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			got, getErr := repo.Get(ctx, created.ID)
+			switch {
+			case err != nil && (getErr != nil || got.Version != 1):
+				t.Errorf("failed change left %+v, %v; want the todo unchanged", got, getErr)
+			case err == nil && getErr == nil && got.Version != 2:
+				t.Errorf("Version = %d after a change, want 2", got.Version)
+			}
+		})
+	}
+}
+
+func updateTitle(ctx context.Context, repo *MemoryRepository, id int, version int64) error {
+	_, err := repo.Update(ctx, Todo{ID: id, Title: "Buy oat milk", Version: version})
+	return err
+}
+
+func toggle(ctx context.Context, repo *MemoryRepository, id int, version int64) error {
+	_, err := repo.Toggle(ctx, id, version, false)
+	return err
+}
+
+func remove(ctx context.Context, repo *MemoryRepository, id int, version int64) error {
+	return repo.Delete(ctx, id, version)
+}
+
+func TestMemoryRepositoryToggle(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := newUser(t, repo, "alice@example.com")
+	created, err := repo.Create(ctx, Todo{Title: "Buy milk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := repo.Toggle(ctx, created.ID, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.Completed || done.CompletedAt == nil {
+		t.Errorf("after one toggle: Completed = %v, CompletedAt = %v", done.Completed, done.CompletedAt)
+	}
+	open, err := repo.Toggle(ctx, created.ID, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open.Completed || open.CompletedAt != nil {
+		t.Errorf("after two toggles: Completed = %v, CompletedAt = %v", open.Completed, open.CompletedAt)
+	}
+}
+
+func TestMemoryRepositoryDelete(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := newUser(t, repo, "alice@example.com")
+	created, err := repo.Create(ctx, Todo{Title: "Buy milk"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Delete(ctx, created.ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: error = %v, want %v", err, ErrNotFound)
+	}
+	if err := repo.Delete(ctx, created.ID, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMemoryRepositoryTrashEventOrder(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := newUser(t, repo, "alice@example.com")
+	parent, err := repo.Create(ctx, Todo{Title: "Clean house"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The parent's events come first, then its subtasks' in id order.
+	want := []int{parent.ID}
+	for i := range 8 {
+		child, err := repo.Create(ctx, Todo{Title: fmt.Sprint(i), ParentID: &parent.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, child.ID)
+	}
+
+	tests := []struct {
+		action EventAction
+		change func() error
+	}{
+		{EventDelete, func() error { return repo.Delete(ctx, parent.ID, 0) }},
+		{EventRestore, func() error {
+			_, err := repo.Restore(ctx, parent.ID)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		latest, err := repo.LatestEventID(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.change(); err != nil {
+			t.Fatal(err)
+		}
+		events, err := repo.EventsAfter(ctx, latest, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for _, e := range events {
+			if e.Action != tt.action {
+				t.Errorf("got a %s event, want only %s", e.Action, tt.action)
+			}
+			got = append(got, e.TodoID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s events for todos %v, want %v", tt.action, got, want)
+		}
+	}
+}
+
+func TestMemoryRepositoryWithinTx(t *testing.T) {
+	errRollback := errors.New("roll back")
+
+	tests := []struct {
+		name string
+		fn   func(ctx context.Context, repo *MemoryRepository) error
+		// want are the titles of the todos left, and nextID the id of the
+		// todo created next: those of rolled back todos are not reused.
+		want   []string
+		nextID int
+	}{
+		{
+			name: "commit",
+			fn: func(ctx context.Context, repo *MemoryRepository) error {
+				return repo.WithinTx(ctx, func(ctx context.Context) error {
+					_, err := repo.Create(ctx, Todo{Title: "kept"})
+					return err
+				})
+			},
+			want:   []string{"kept"},
+			nextID: 2,
+		},
+		{
+			name: "rollback",
+			fn: func(ctx context.Context, repo *MemoryRepository) error {
+				return repo.WithinTx(ctx, func(ctx context.Context) error {
+					if _, err := repo.Create(ctx, Todo{Title: "dropped"}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+			nextID: 2,
+		},
+		{
+			name: "nested rollback",
+			fn: func(ctx context.Context, repo *MemoryRepository) error {
+				return repo.WithinTx(ctx, func(ctx context.Context) error {
+					if _, err := repo.Create(ctx, Todo{Title: "outer"}); err != nil {
+						return err
+					}
+					err := repo.WithinTx(ctx, func(ctx context.Context) error {
+						if _, err := repo.Create(ctx, Todo{Title: "inner"}); err != nil {
+							return err
+						}
+						return errRollback
+					})
+					if !errors.Is(err, errRollback) {
+						return fmt.Errorf("inner WithinTx: %v", err)
+					}
+					return nil
+				})
+			},
+			want:   []string{"outer"},
+			nextID: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryRepository()
+			ctx := newUser(t, repo, "alice@example.com")
+
+			if err := tt.fn(ctx, repo); err != nil && !errors.Is(err, errRollback) {
+				t.Fatal(err)
+			}
+			page, err := repo.List(ctx, ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, todo := range page.Todos {
+				got = append(got, todo.Title)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("titles = %q, want %q", got, tt.want)
+			}
+
+			next, err := repo.Create(ctx, Todo{Title: "next"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next.ID != tt.nextID {
+				t.Errorf("next id = %d, want %d", next.ID, tt.nextID)
+			}
+		})
+	}
+}
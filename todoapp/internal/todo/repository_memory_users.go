@@ -0,0 +1,55 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+func (r *MemoryRepository) CreateUser(ctx context.Context, email, passwordHash string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	defer r.lock(ctx)()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			return User{}, fmt.Errorf("%w: email %s is already registered", ErrConflict, email)
+		}
+	}
+	u := User{ID: r.nextUserID, Email: email, CreatedAt: time.Now(), PasswordHash: passwordHash}
+	r.nextUserID++
+	r.users[u.ID] = u
+
+	return u, nil
+}
+
+func (r *MemoryRepository) UserByEmail(ctx context.Context, email string) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (r *MemoryRepository) UserByID(ctx context.Context, id int) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	defer r.rlock(ctx)()
+
+	u, ok := r.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return u, nil
+}
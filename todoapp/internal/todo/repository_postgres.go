@@ -2,119 +2,955 @@ package todo
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type PostgresRepository struct {
 	DB *pgxpool.Pool
+
+	// QueryTimeout bounds every query on top of the caller's context.
+	// Zero means no additional deadline.
+	QueryTimeout time.Duration
 }
 
 func NewPostgresRepository(db *pgxpool.Pool) *PostgresRepository {
 	return &PostgresRepository{DB: db}
 }
 
-func (r *PostgresRepository) List() ([]Todo, error) {
-	rows, err := r.DB.Query(context.Background(),
-		`SELECT id, title, completed, created_at, completed_at FROM todos ORDER BY id`,
-	)
+// dbtx is implemented by both *pgxpool.Pool and pgx.Tx, for queries that
+// run inside and outside transactions. Begin on a pgx.Tx starts a
+// savepoint.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// todoColumns is the column list scanned by scanTodo. It may be used
+// wherever the todos table is in scope under its own name, including
+// RETURNING clauses.
+const todoColumns = `id, owner_id, title, completed, created_at, completed_at, due_at, remind_at, priority, position, list_id, parent_id, recurrence, deleted_at, version,
+	(SELECT json_build_object('completed', count(*) FILTER (WHERE sub.completed), 'total', count(*))
+	   FROM todos sub WHERE sub.parent_id = todos.id AND sub.deleted_at IS NULL HAVING count(*) > 0),
+	COALESCE((SELECT json_agg(json_build_object('id', tags.id, 'name', tags.name) ORDER BY tags.name)
+	          FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+	          WHERE todo_tags.todo_id = todos.id), '[]')`
+
+// todoDest returns scan destinations for todoColumns.
+func todoDest(t *Todo) []any {
+	return []any{&t.ID, &t.OwnerID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt, &t.DueAt, &t.RemindAt, &t.Priority, &t.Position, &t.ListID, &t.ParentID, &t.Recurrence, &t.DeletedAt, &t.Version, &t.Progress, &t.Tags}
+}
+
+// scanTodo scans a row selected with todoColumns.
+func scanTodo(row pgx.Row) (Todo, error) {
+	var t Todo
+	err := row.Scan(todoDest(&t)...)
+	return t, translateError(err)
+}
+
+// accessibleTo returns a condition on the todos table that holds for the
+// todos the user in query parameter user has access to: those in their
+// inbox and those in lists they are a member of, whether trashed or not.
+func accessibleTo(user string) string {
+	return `(CASE WHEN todos.list_id IS NULL THEN todos.owner_id = ` + user + `
+	 ELSE todos.list_id IN (SELECT list_id FROM list_members WHERE user_id = ` + user + `) END)`
+}
+
+// visibleTo is accessibleTo without the todos in the trash.
+func visibleTo(user string) string {
+	return `(todos.deleted_at IS NULL AND ` + accessibleTo(user) + `)`
+}
+
+// editableBy is accessibleTo without the todos of lists where the user is
+// only a viewer. It keeps the changes that spread from a todo to its
+// subtasks to those the user could make one by one.
+func editableBy(user string) string {
+	return `(CASE WHEN todos.list_id IS NULL THEN todos.owner_id = ` + user + `
+	 ELSE todos.list_id IN (SELECT list_id FROM list_members WHERE user_id = ` + user + ` AND role <> 'viewer') END)`
+}
+
+func (r *PostgresRepository) List(ctx context.Context, opts ListOptions) (Page, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	owner, err := currentUser(ctx)
 	if err != nil {
-		return nil, err
+		return Page{}, err
+	}
+	cur, err := opts.cursor()
+	if err != nil {
+		return Page{}, err
+	}
+
+	var where []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where = append(where, accessibleTo(arg(owner)))
+	if opts.Trashed {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	if opts.Completed != nil {
+		where = append(where, "completed = "+arg(*opts.Completed))
+	}
+	if opts.CreatedAfter != nil {
+		where = append(where, "created_at > "+arg(*opts.CreatedAfter))
+	}
+	if opts.CreatedBefore != nil {
+		where = append(where, "created_at < "+arg(*opts.CreatedBefore))
+	}
+	if opts.CompletedAfter != nil {
+		where = append(where, "completed_at > "+arg(*opts.CompletedAfter))
+	}
+	if opts.CompletedBefore != nil {
+		where = append(where, "completed_at < "+arg(*opts.CompletedBefore))
+	}
+	if opts.DueAfter != nil {
+		where = append(where, "due_at > "+arg(*opts.DueAfter))
+	}
+	if opts.DueBefore != nil {
+		where = append(where, "due_at < "+arg(*opts.DueBefore))
+	}
+	if opts.ListID != nil {
+		where = append(where, "list_id = "+arg(*opts.ListID))
+	}
+	if opts.Inbox {
+		where = append(where, "list_id IS NULL")
+	}
+	if opts.ParentID != nil {
+		where = append(where, "parent_id = "+arg(*opts.ParentID))
+	}
+	if opts.TopLevel {
+		where = append(where, "parent_id IS NULL")
+	}
+	if len(opts.Tags) > 0 {
+		names := make([]string, 0, len(opts.Tags))
+		for _, name := range opts.Tags {
+			if n := strings.ToLower(name); !slices.Contains(names, n) {
+				names = append(names, n)
+			}
+		}
+		matching := `SELECT count(*) FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+			WHERE todo_tags.todo_id = todos.id AND lower(tags.name) = ANY(` + arg(names) + `)`
+		if opts.MatchAnyTag {
+			where = append(where, "("+matching+") > 0")
+		} else {
+			where = append(where, "("+matching+") = "+arg(len(names)))
+		}
+	}
+
+	col := string(opts.sortField())
+	dir, op := "ASC", ">"
+	if opts.Descending {
+		dir, op = "DESC", "<"
+	}
+
+	if cur != nil {
+		key, _ := cur.key()
+		switch opts.sortField() {
+		case SortByID:
+			where = append(where, "id "+op+" "+arg(key.ID))
+		case SortByCompletedAt, SortByDueAt:
+			// Nullable columns sort NULLS LAST in both directions.
+			if v := key.sortTime(opts.sortField()); v == nil {
+				where = append(where, fmt.Sprintf("(%s IS NULL AND id %s %s)", col, op, arg(key.ID)))
+			} else {
+				where = append(where, fmt.Sprintf("((%s, id) %s (%s, %s) OR %s IS NULL)",
+					col, op, arg(*v), arg(key.ID), col))
+			}
+		case SortByCreatedAt:
+			where = append(where, fmt.Sprintf("(created_at, id) %s (%s, %s)", op, arg(key.CreatedAt), arg(key.ID)))
+		case SortByTitle:
+			where = append(where, fmt.Sprintf("(title, id) %s (%s, %s)", op, arg(key.Title), arg(key.ID)))
+		case SortByPriority:
+			where = append(where, fmt.Sprintf("(priority, id) %s (%s, %s)", op, arg(key.Priority), arg(key.ID)))
+		case SortByPosition:
+			where = append(where, fmt.Sprintf("(position, id) %s (%s, %s)", op, arg(key.Position), arg(key.ID)))
+		}
+	}
+
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE ` + strings.Join(where, " AND ")
+	if col == "id" {
+		query += fmt.Sprintf(" ORDER BY id %s", dir)
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id %s", col, dir, dir)
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	query += " LIMIT " + arg(opts.limit()+1)
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return Page{}, translateError(err)
 	}
 	defer rows.Close()
 
 	var todos []Todo
 
 	for rows.Next() {
-		var t Todo
-		err := rows.Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt)
+		t, err := scanTodo(rows)
 		if err != nil {
-			return nil, err
+			return Page{}, err
 		}
 		todos = append(todos, t)
 	}
+	if err := rows.Err(); err != nil {
+		return Page{}, translateError(err)
+	}
+
+	return newPage(todos, opts), nil
+}
+
+// Create inserts a new, uncompleted todo from the title, due dates,
+// priority, list, parent and recurrence of t. It is positioned after every
+// existing todo and owned by the current user.
+func (r *PostgresRepository) Create(ctx context.Context, t Todo) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	return todos, nil
+	t.OwnerID = owner
+	var created Todo
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		created, err = insertTodo(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, EventCreate, nil, &created)
+	})
+
+	return created, translateError(err)
+}
+
+// insertTodo inserts t for t.OwnerID, created now unless t.CreatedAt is
+// set, after the other todos of its position scope. It must run in a
+// transaction, which holds the scope's lock until it ends.
+func insertTodo(ctx context.Context, tx pgx.Tx, t Todo) (Todo, error) {
+	var createdAt *time.Time
+	if !t.CreatedAt.IsZero() {
+		createdAt = &t.CreatedAt
+	}
+	scope := scopeOf(t)
+	if err := lockPositions(ctx, tx, scope); err != nil {
+		return Todo{}, err
+	}
+	return scanTodo(tx.QueryRow(ctx,
+		`INSERT INTO todos (owner_id, title, completed, created_at, completed_at, due_at, remind_at, priority, list_id, parent_id, recurrence, position)
+		 VALUES ($1, $2, $3, COALESCE($4, NOW()), $5, $6, $7, $8, $9, $10, $11,
+		         (SELECT COALESCE(MAX(position), 0) + $12 FROM todos WHERE `+scope.where("$13")+`))
+		 RETURNING `+todoColumns,
+		t.OwnerID, t.Title, t.Completed, createdAt, t.CompletedAt, t.DueAt, t.RemindAt, t.Priority, t.ListID, t.ParentID, t.Recurrence, positionGap,
+		scope.id,
+	))
 }
 
-func (r *PostgresRepository) Create(title string) (Todo, error) {
+func (r *PostgresRepository) Get(ctx context.Context, id int) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return scanTodo(r.db(ctx).QueryRow(ctx,
+		`SELECT `+todoColumns+` FROM todos WHERE id=$1 AND `+visibleTo("$2"),
+		id, owner,
+	))
+}
+
+// Update overwrites the mutable fields of the todo with id t.ID if it is
+// still at t.Version. A todo moved to another list or inbox goes after
+// the todos already there; otherwise its position is only changed by
+// Move. Changing remind_at re-arms its reminder, and completing a
+// recurring todo creates its next occurrence in the same transaction.
+func (r *PostgresRepository) Update(ctx context.Context, t Todo) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var updated Todo
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		before, err := lockTodo(ctx, tx, t.ID, owner)
+		if err != nil {
+			return err
+		}
+		position := before.Position
+		if scope := scopeOf(Todo{OwnerID: before.OwnerID, ListID: t.ListID}); scope != scopeOf(before) {
+			if err := lockPositions(ctx, tx, scope); err != nil {
+				return err
+			}
+			err := tx.QueryRow(ctx,
+				`SELECT COALESCE(MAX(position), 0) + $1 FROM todos WHERE `+scope.where("$2"),
+				positionGap, scope.id,
+			).Scan(&position)
+			if err != nil {
+				return err
+			}
+		}
+
+		updated, err = scanTodo(tx.QueryRow(ctx,
+			`UPDATE todos
+			  SET title=$1, completed=$2, completed_at=$3, due_at=$4, remind_at=$5, priority=$6, list_id=$7, parent_id=$8,
+			      recurrence=$9, position=$10, version = version + 1,
+			      reminded_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminded_at END
+			  WHERE id=$11 AND version=$12
+			  RETURNING `+todoColumns,
+			t.Title, t.Completed, t.CompletedAt, t.DueAt, t.RemindAt, t.Priority, t.ListID, t.ParentID,
+			t.Recurrence, position, t.ID, t.Version,
+		))
+		if errors.Is(err, ErrNotFound) {
+			// The todo is locked and visible, so only its version can
+			// have failed to match.
+			return staleVersion(t.ID, t.Version)
+		}
+		if err != nil {
+			return err
+		}
+		if !before.Completed && updated.Completed && updated.Recurrence != "" {
+			if updated, err = spawnNext(ctx, tx, updated); err != nil {
+				return err
+			}
+		}
+		return recordEvent(ctx, tx, EventUpdate, &before, &updated)
+	})
+
+	return updated, translateError(err)
+}
+
+// lockTodo locks a todo the user can see for the rest of the transaction
+// and returns it as it was before the change.
+func lockTodo(ctx context.Context, tx pgx.Tx, id, user int) (Todo, error) {
+	tag, err := tx.Exec(ctx,
+		`SELECT 1 FROM todos WHERE id=$1 AND `+visibleTo("$2")+` FOR UPDATE`,
+		id, user,
+	)
+	if err != nil {
+		return Todo{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Todo{}, ErrNotFound
+	}
+	return scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, id))
+}
+
+// spawnNext creates the next occurrence of the recurring todo t, which
+// was just completed, and hands the recurrence rule over to it. It
+// returns t as updated.
+func spawnNext(ctx context.Context, tx pgx.Tx, t Todo) (Todo, error) {
+	done := time.Now()
+	if t.CompletedAt != nil {
+		done = *t.CompletedAt
+	}
+	next, err := t.nextOccurrence(done)
+	if err != nil {
+		return Todo{}, err
+	}
+	next, err = insertTodo(ctx, tx, next)
+	if err != nil {
+		return Todo{}, err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO todo_tags (todo_id, tag_id) SELECT $1, tag_id FROM todo_tags WHERE todo_id=$2`,
+		next.ID, t.ID,
+	)
+	if err != nil {
+		return Todo{}, err
+	}
+	next, err = scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, next.ID))
+	if err != nil {
+		return Todo{}, err
+	}
+	if err := recordEvent(ctx, tx, EventCreate, nil, &next); err != nil {
+		return Todo{}, err
+	}
+	return scanTodo(tx.QueryRow(ctx,
+		`UPDATE todos SET recurrence='', version = version + 1 WHERE id=$1 RETURNING `+todoColumns,
+		t.ID,
+	))
+}
+
+// Delete moves the todo and its subtasks to the trash if the todo is at
+// version, or at any version if it is zero. They all get the same
+// deleted_at, which is how Restore finds them again.
+func (r *PostgresRepository) Delete(ctx context.Context, id int, version int64) error {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx,
+			`WITH RECURSIVE subtree AS (
+			     SELECT id FROM todos WHERE id=$1 AND `+visibleTo("$2")+` AND ($3::bigint = 0 OR version = $3)
+			     UNION
+			     SELECT todos.id FROM todos JOIN subtree ON todos.parent_id = subtree.id
+			     WHERE todos.deleted_at IS NULL AND `+editableBy("$2")+`
+			 )
+			 UPDATE todos SET deleted_at = NOW(), version = version + 1 WHERE id IN (SELECT id FROM subtree)
+			 RETURNING `+todoColumns,
+			id, owner, version,
+		)
+		if err != nil {
+			return err
+		}
+		trashed, err := scanTodos(rows)
+		if err != nil {
+			return err
+		}
+		if len(trashed) == 0 {
+			return missingOrStale(ctx, tx, id, owner, version)
+		}
+		return recordEvents(ctx, tx, EventDelete, trashed, func(t Todo) Todo {
+			t.DeletedAt = nil
+			t.Version--
+			return t
+		})
+	})
+
+	return translateError(err)
+}
+
+// missingOrStale explains why a change of todo id conditional on version
+// matched no rows: ErrNotFound if the user cannot see the todo and
+// ErrPreconditionFailed if it has moved on from version.
+func missingOrStale(ctx context.Context, db dbtx, id, user int, version int64) error {
+	var exists bool
+	err := db.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM todos WHERE id=$1 AND `+visibleTo("$2")+`)`,
+		id, user,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return staleVersion(id, version)
+}
+
+// GetTrashed returns a todo in the trash.
+func (r *PostgresRepository) GetTrashed(ctx context.Context, id int) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return scanTodo(r.db(ctx).QueryRow(ctx,
+		`SELECT `+todoColumns+` FROM todos WHERE id=$1 AND deleted_at IS NOT NULL AND `+accessibleTo("$2"),
+		id, owner,
+	))
+}
+
+// Restore takes a todo out of the trash together with the subtasks that
+// were deleted with it. A subtask whose parent is still in the trash
+// cannot be restored on its own.
+func (r *PostgresRepository) Restore(ctx context.Context, id int) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var t Todo
-	err := r.DB.QueryRow(context.Background(),
-		`INSERT INTO todos (title, completed, created_at)
-		 VALUES ($1, false, NOW())
-		 RETURNING id, title, completed, created_at, completed_at`,
-		title,
-	).Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt)
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		var deletedAt time.Time
+		var parentTrashed bool
+		err := tx.QueryRow(ctx,
+			`SELECT deleted_at,
+			        EXISTS (SELECT 1 FROM todos parent WHERE parent.id = todos.parent_id AND parent.deleted_at IS NOT NULL)
+			 FROM todos WHERE id=$1 AND deleted_at IS NOT NULL AND `+accessibleTo("$2")+`
+			 FOR UPDATE`,
+			id, owner,
+		).Scan(&deletedAt, &parentTrashed)
+		if err != nil {
+			return err
+		}
+		if parentTrashed {
+			return fmt.Errorf("%w: the parent of todo %d is in the trash; restore it first", ErrConflict, id)
+		}
+
+		rows, err := tx.Query(ctx,
+			`WITH RECURSIVE subtree AS (
+			     SELECT id FROM todos WHERE id=$1
+			     UNION
+			     SELECT todos.id FROM todos JOIN subtree ON todos.parent_id = subtree.id
+			     WHERE todos.deleted_at = $2 AND `+editableBy("$3")+`
+			 )
+			 UPDATE todos SET deleted_at = NULL, version = version + 1 WHERE id IN (SELECT id FROM subtree)
+			 RETURNING `+todoColumns,
+			id, deletedAt, owner,
+		)
+		if err != nil {
+			return err
+		}
+		restored, err := scanTodos(rows)
+		if err != nil {
+			return err
+		}
+		err = recordEvents(ctx, tx, EventRestore, restored, func(t Todo) Todo {
+			t.DeletedAt = &deletedAt
+			t.Version--
+			return t
+		})
+		if err != nil {
+			return err
+		}
+		t, err = scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, id))
+		return err
+	})
+
+	return t, translateError(err)
+}
+
+// PurgeTrash permanently deletes the todos that were moved to the trash
+// before cutoff, with their subtasks, and records their purge on behalf
+// of the server. It runs on behalf of the purger and covers every user's
+// todos.
+func (r *PostgresRepository) PurgeTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var n int
+	err := beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		// The foreign key deletes the subtasks too, so they are
+		// selected explicitly to be recorded.
+		rows, err := tx.Query(ctx,
+			`WITH RECURSIVE purged AS (
+			     SELECT id FROM todos WHERE deleted_at < $1
+			     UNION
+			     SELECT todos.id FROM todos JOIN purged ON todos.parent_id = purged.id
+			 )
+			 SELECT `+todoColumns+` FROM todos WHERE id IN (SELECT id FROM purged) ORDER BY id
+			 FOR UPDATE`,
+			cutoff,
+		)
+		if err != nil {
+			return err
+		}
+		purged, err := scanTodos(rows)
+		if err != nil {
+			return err
+		}
+		for _, t := range purged {
+			if err := appendEvent(ctx, tx, newEvent(0, EventPurge, &t, nil)); err != nil {
+				return err
+			}
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM todos WHERE id = ANY($1)`, todoIDs(purged))
+		n = int(tag.RowsAffected())
+		return err
+	})
 
-	return t, err
+	return n, translateError(err)
 }
 
-func (r *PostgresRepository) Get(id int) (Todo, error) {
+// todoIDs returns the ids of todos.
+func todoIDs(todos []Todo) []int {
+	ids := make([]int, len(todos))
+	for i, t := range todos {
+		ids[i] = t.ID
+	}
+	return ids
+}
+
+// Toggle flips the completion state of a todo if it is at version, or at
+// any version if it is zero. Completing a recurring todo creates its next
+// occurrence in the same transaction.
+func (r *PostgresRepository) Toggle(ctx context.Context, id int, version int64, cascade bool) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var t Todo
-	err := r.DB.QueryRow(context.Background(),
-		`SELECT id, title, completed, created_at, completed_at FROM todos WHERE id=$1`,
-		id,
-	).Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt)
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		before, err := lockTodo(ctx, tx, id, owner)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx,
+			`UPDATE todos
+			 SET completed = NOT completed,
+			     completed_at = CASE
+			         WHEN completed = false THEN NOW()
+			         ELSE NULL
+			     END,
+			     version = version + 1
+			 WHERE id=$1 AND ($2::bigint = 0 OR version = $2)`,
+			id, version,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return staleVersion(id, version)
+		}
 
-	return t, err
+		if cascade && !before.Completed {
+			rows, err := tx.Query(ctx,
+				`WITH RECURSIVE descendants AS (
+				     SELECT id FROM todos WHERE parent_id=$1 AND deleted_at IS NULL AND `+editableBy("$2")+`
+				     UNION
+				     SELECT todos.id FROM todos JOIN descendants ON todos.parent_id = descendants.id
+				     WHERE todos.deleted_at IS NULL AND `+editableBy("$2")+`
+				 )
+				 UPDATE todos SET completed = true, completed_at = NOW(), version = version + 1
+				 WHERE id IN (SELECT id FROM descendants) AND NOT completed
+				 RETURNING `+todoColumns,
+				id, owner,
+			)
+			if err != nil {
+				return err
+			}
+			completed, err := scanTodos(rows)
+			if err != nil {
+				return err
+			}
+			for _, c := range completed {
+				before := c
+				before.Completed, before.CompletedAt = false, nil
+				before.Version--
+				if c.Recurrence != "" {
+					if c, err = spawnNext(ctx, tx, c); err != nil {
+						return err
+					}
+				}
+				if err := recordEvent(ctx, tx, EventToggle, &before, &c); err != nil {
+					return err
+				}
+			}
+		}
+
+		t, err = scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, id))
+		if err != nil {
+			return err
+		}
+		if t.Completed && t.Recurrence != "" {
+			if t, err = spawnNext(ctx, tx, t); err != nil {
+				return err
+			}
+		}
+		return recordEvent(ctx, tx, EventToggle, &before, &t)
+	})
+
+	return t, translateError(err)
+}
+
+func (r *PostgresRepository) Ancestors(ctx context.Context, id int) ([]int, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// The chain starts with the todo itself so a missing todo yields no
+	// rows. The depth bound stops the walk should a cycle exist.
+	rows, err := r.db(ctx).Query(ctx,
+		`WITH RECURSIVE chain AS (
+		     SELECT id, parent_id, 0 AS depth FROM todos WHERE id=$1 AND `+visibleTo("$3")+`
+		     UNION ALL
+		     SELECT todos.id, todos.parent_id, chain.depth + 1
+		     FROM todos JOIN chain ON todos.id = chain.parent_id
+		     WHERE chain.depth < $2
+		 )
+		 SELECT id FROM chain ORDER BY depth`,
+		id, maxDepth, owner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var ancestor int
+		if err := rows.Scan(&ancestor); err != nil {
+			return nil, translateError(err)
+		}
+		ids = append(ids, ancestor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+	if len(ids) == 0 {
+		return nil, ErrNotFound
+	}
+	return ids[1:], nil
 }
 
-func (r *PostgresRepository) Update(id int, title string, completed bool, completedAt *time.Time) (Todo, error) {
+// Move places the todo immediately before or after the anchor todo in
+// the anchor's position scope. Moves and inserts hold the advisory locks
+// of the scopes they touch, so concurrent ones always see each other's
+// positions.
+func (r *PostgresRepository) Move(ctx context.Context, id, anchorID int, after bool) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var t Todo
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		// The row lock comes before the advisory locks, as it does when
+		// a toggle inserts the next occurrence of a recurring todo.
+		moved, err := lockTodo(ctx, tx, id, owner)
+		if err != nil {
+			return err
+		}
+		anchor, err := scanTodo(tx.QueryRow(ctx,
+			`SELECT `+todoColumns+` FROM todos WHERE id=$1 AND `+visibleTo("$2"),
+			anchorID, owner,
+		))
+		if err != nil {
+			return err
+		}
+		scope := scopeOf(anchor)
+		if scope != scopeOf(moved) {
+			return errOtherScope(id, anchorID)
+		}
+		if err := lockPositions(ctx, tx, scope); err != nil {
+			return err
+		}
 
-	err := r.DB.QueryRow(context.Background(),
-		`UPDATE todos 
-		  SET title=$1, completed=$2, completed_at=$3 
-		  WHERE id=$4
-		  RETURNING id, title, completed, created_at, completed_at`,
-		title, completed, completedAt, id,
-	).Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt)
+		pos, err := movePosition(ctx, tx, scope, id, anchorID, after)
+		if errors.Is(err, errNoGap) {
+			// Spread the scope's todos out again and retry once.
+			_, err = tx.Exec(ctx,
+				`UPDATE todos SET position = s.rn * $1
+				 FROM (SELECT id, row_number() OVER (ORDER BY position, id) AS rn
+				       FROM todos WHERE `+scope.where("$2")+`) s
+				 WHERE todos.id = s.id`,
+				positionGap, scope.id,
+			)
+			if err != nil {
+				return err
+			}
+			pos, err = movePosition(ctx, tx, scope, id, anchorID, after)
+		}
+		if err != nil {
+			return err
+		}
+
+		t, err = scanTodo(tx.QueryRow(ctx,
+			`UPDATE todos SET position=$1, version = version + 1 WHERE id=$2 RETURNING `+todoColumns,
+			pos, id,
+		))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, EventMove, &moved, &t)
+	})
 
-	return t, err
+	return t, translateError(err)
 }
 
-func (r *PostgresRepository) Delete(id int) error {
-	_, err := r.DB.Exec(context.Background(), `DELETE FROM todos WHERE id=$1`, id)
+// lockPositions takes the advisory lock of scope for the rest of the
+// transaction.
+func lockPositions(ctx context.Context, tx pgx.Tx, scope positionScope) error {
+	class, id := scope.lockKey()
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, $2)`, class, id)
 	return err
 }
 
-func (r *PostgresRepository) Toggle(id int) (Todo, error) {
-	var t Todo
-	err := r.DB.QueryRow(context.Background(),
-		`UPDATE todos
-		 SET completed = NOT completed,
-		     completed_at = CASE 
-		         WHEN completed = false THEN NOW()
-		         ELSE NULL 
-		     END
-		 WHERE id=$1
-		 RETURNING id, title, completed, created_at, completed_at`,
-		id,
-	).Scan(&t.ID, &t.Title, &t.Completed, &t.CreatedAt, &t.CompletedAt)
+// movePosition computes the position between the anchor and its
+// neighbour on the requested side among the todos of scope, ignoring the
+// todo being moved.
+func movePosition(ctx context.Context, tx pgx.Tx, scope positionScope, id, anchorID int, after bool) (int64, error) {
+	var anchorPos int64
+	err := tx.QueryRow(ctx, `SELECT position FROM todos WHERE id=$1`, anchorID).Scan(&anchorPos)
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	query := `SELECT position FROM todos
+		WHERE (position, id) > ($1, $2) AND id <> $3 AND deleted_at IS NULL AND ` + scope.where("$4") + `
+		ORDER BY position, id LIMIT 1`
+	if !after {
+		query = `SELECT position FROM todos
+		WHERE (position, id) < ($1, $2) AND id <> $3 AND deleted_at IS NULL AND ` + scope.where("$4") + `
+		ORDER BY position DESC, id DESC LIMIT 1`
+	}
+	var neighbour int64
+	err = tx.QueryRow(ctx, query, anchorPos, anchorID, id, scope.id).Scan(&neighbour)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return endPosition(anchorPos, after), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return midPosition(anchorPos, neighbour)
+}
+
+// ClaimReminders marks up to limit reminders that are due at now as sent
+// and returns their todos. Rows locked by another replica are skipped, so
+// each reminder is claimed once. It runs on behalf of the scheduler and
+// covers every user's todos.
+func (r *PostgresRepository) ClaimReminders(ctx context.Context, now time.Time, limit int) ([]Todo, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`UPDATE todos SET reminded_at = $1
+		 WHERE id IN (
+		     SELECT id FROM todos
+		     WHERE remind_at <= $1 AND reminded_at IS NULL AND NOT completed AND deleted_at IS NULL
+		     ORDER BY remind_at
+		     LIMIT $2
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+todoColumns,
+		now, limit,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
 
-	return t, err
+	var todos []Todo
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	return todos, translateError(rows.Err())
+}
+
+// Search ranks todos against a web-style query ("quoted phrases", -not,
+// or) using the search_vector column.
+func (r *PostgresRepository) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+todoColumns+`,
+		        ts_rank(search_vector, q) AS rank,
+		        ts_headline('english', title, q, $3) AS snippet
+		 FROM todos, websearch_to_tsquery('english', $1) AS q
+		 WHERE search_vector @@ q AND `+visibleTo("$4")+`
+		 ORDER BY rank DESC, id
+		 LIMIT $2`,
+		query, searchLimit(limit), pgHeadlineOptions, owner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var res SearchResult
+		err := rows.Scan(append(todoDest(&res.Todo), &res.Rank, &res.Snippet)...)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		res.Snippet = escapeHeadline(res.Snippet)
+		results = append(results, res)
+	}
+
+	return results, translateError(rows.Err())
 }
 
 // Ping checks if the database is accessible
-func (r *PostgresRepository) Ping() error {
-	return r.DB.Ping(context.Background())
-}
-
-// CreateTableIfNotExists creates the todos table if it doesn't exist
-func (r *PostgresRepository) CreateTableIfNotExists() error {
-	_, err := r.DB.Exec(context.Background(), `
-		CREATE TABLE IF NOT EXISTS todos (
-			id SERIAL PRIMARY KEY,
-			title TEXT NOT NULL,
-			completed BOOLEAN NOT NULL DEFAULT false,
-			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-			completed_at TIMESTAMP WITH TIME ZONE
-		)
-	`)
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.DB.Ping(ctx)
+}
+
+// txKey is the context key under which WithinTx stores its transaction.
+type txKey struct{}
+
+// WithinTx runs fn in a transaction that every method called with the
+// context fn is given takes part in. The transaction commits if fn
+// returns nil and rolls back otherwise. Inside another WithinTx, fn runs
+// in a savepoint of the outer transaction instead.
+func (r *PostgresRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	return translateError(err)
+}
+
+// beginFunc is pgx.BeginFunc that also forgets the events recorded in
+// the transaction if it rolls back.
+func beginFunc(ctx context.Context, db dbtx, fn func(pgx.Tx) error) error {
+	rollback := recorderFrom(ctx).mark()
+	err := pgx.BeginFunc(ctx, db, fn)
 	if err != nil {
-		log.Printf("Error creating todos table: %v", err)
+		rollback()
+	}
+	return err
+}
+
+// db returns the transaction WithinTx stored in ctx, or the pool outside
+// of one.
+func (r *PostgresRepository) db(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return r.DB
+}
+
+// withTimeout applies QueryTimeout to ctx.
+func (r *PostgresRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.QueryTimeout)
+}
+
+// translateError maps driver errors onto the package's domain errors.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505", "23503": // unique_violation, foreign_key_violation
+			return fmt.Errorf("%w: %s", ErrConflict, pgErr.Detail)
+		case "23502", "23514", "22001": // not_null_violation, check_violation, string_data_right_truncation
+			return fmt.Errorf("%w: %s", ErrValidation, pgErr.Message)
+		}
 	}
 	return err
 }
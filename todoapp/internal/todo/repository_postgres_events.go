@@ -0,0 +1,187 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordEvent appends an event for a change the current user made to a
+// todo. It is called with the transaction that made the change.
+func recordEvent(ctx context.Context, db dbtx, action EventAction, before, after *Todo) error {
+	actor, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	return appendEvent(ctx, db, newEvent(actor, action, before, after))
+}
+
+// appendEvent appends e to the audit log, notifies the other processes of
+// it and passes it to the recorder in ctx.
+func appendEvent(ctx context.Context, db dbtx, e Event) error {
+	err := db.QueryRow(ctx,
+		`INSERT INTO todo_events (todo_id, owner_id, list_id, tag_id, actor_id, action, name, before, after, created_at)
+		 VALUES (NULLIF($1, 0), $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, NOW())
+		 RETURNING id, created_at`,
+		e.TodoID, e.ownerID, e.ListID, e.TagID, e.ActorID, e.Action, e.Name, e.Before, e.After,
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := notifyEvent(ctx, db, e.ID); err != nil {
+		return err
+	}
+	recorderFrom(ctx).add(e)
+	return nil
+}
+
+// eventColumns are the columns of todo_events read by scanEvent.
+const eventColumns = `id, COALESCE(todo_id, 0), owner_id, list_id, tag_id, actor_id, action, COALESCE(name, ''), before, after, created_at`
+
+// scanEvent reads an event selected with eventColumns.
+func scanEvent(row pgx.Row) (Event, error) {
+	var e Event
+	err := row.Scan(&e.ID, &e.TodoID, &e.ownerID, &e.ListID, &e.TagID, &e.ActorID, &e.Action, &e.Name, &e.Before, &e.After, &e.CreatedAt)
+	return e, err
+}
+
+// recordEvents appends an event for each todo in after, whose state
+// before the change is given by before.
+func recordEvents(ctx context.Context, db dbtx, action EventAction, after []Todo, before func(Todo) Todo) error {
+	for _, t := range after {
+		old := before(t)
+		if err := recordEvent(ctx, db, action, &old, &t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanTodos collects the todos in rows selected with todoColumns.
+func scanTodos(rows pgx.Rows) ([]Todo, error) {
+	defer rows.Close()
+
+	var todos []Todo
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, t)
+	}
+	return todos, translateError(rows.Err())
+}
+
+func (r *PostgresRepository) ListEvents(ctx context.Context, f EventFilter) (EventPage, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return EventPage{}, err
+	}
+	cursor, err := f.cursor()
+	if err != nil {
+		return EventPage{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	query := `SELECT ` + eventColumns + ` FROM todo_events WHERE ` + eventReadableBy(arg(user))
+	if f.TodoID != nil {
+		query += " AND todo_id = " + arg(*f.TodoID)
+	}
+	if f.ListID != nil {
+		query += " AND list_id = " + arg(*f.ListID)
+	}
+	if f.ActorID != nil {
+		query += " AND actor_id = " + arg(*f.ActorID)
+	}
+	if f.Action != "" {
+		query += " AND action = " + arg(f.Action)
+	}
+	if f.After != nil {
+		query += " AND created_at > " + arg(*f.After)
+	}
+	if f.Before != nil {
+		query += " AND created_at < " + arg(*f.Before)
+	}
+	if cursor != 0 {
+		query += " AND id < " + arg(cursor)
+	}
+	// Fetch one extra row to learn whether there is a next page.
+	query += " ORDER BY id DESC LIMIT " + arg(f.limit()+1)
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return EventPage{}, translateError(err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return EventPage{}, translateError(err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return EventPage{}, translateError(err)
+	}
+
+	return newEventPage(events, f), nil
+}
+
+// eventReadableBy returns a condition on the todo_events table that holds
+// for the events readable by the user in query parameter user: those of
+// changes they made, and of todos they had access to when the event was
+// recorded or have access to now.
+func eventReadableBy(user string) string {
+	return `(actor_id = ` + user + `
+		OR (CASE WHEN list_id IS NULL THEN owner_id = ` + user + `
+		    ELSE list_id IN (SELECT list_id FROM list_members WHERE user_id = ` + user + `) END)
+		OR todo_id IN (SELECT id FROM todos WHERE ` + accessibleTo(user) + `))`
+}
+
+func (r *PostgresRepository) EventsAfter(ctx context.Context, after int64, limit int) ([]Event, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+eventColumns+` FROM todo_events
+		 WHERE id > $2 AND `+eventReadableBy("$1")+`
+		 ORDER BY id LIMIT $3`,
+		user, after, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, translateError(err)
+		}
+		events = append(events, e)
+	}
+	return events, translateError(rows.Err())
+}
+
+func (r *PostgresRepository) LatestEventID(ctx context.Context) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var id int64
+	err := r.db(ctx).QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM todo_events`).Scan(&id)
+	return id, translateError(err)
+}
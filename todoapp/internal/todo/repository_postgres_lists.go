@@ -0,0 +1,220 @@
+package todo
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// listColumns selects the lists the user in query parameter $1 is a
+// member of, with the user's role and the lists' todo counts. It must be
+// used with listGroupBy.
+const listColumns = `lists.id, lists.owner_id, lists.name, lists.created_at, list_members.role,
+	count(todos.id) FILTER (WHERE NOT todos.completed),
+	count(todos.id) FILTER (WHERE todos.completed)
+	FROM lists
+	JOIN list_members ON list_members.list_id = lists.id AND list_members.user_id = $1
+	LEFT JOIN todos ON todos.list_id = lists.id AND todos.deleted_at IS NULL`
+
+const listGroupBy = ` GROUP BY lists.id, list_members.role`
+
+func scanList(row pgx.Row) (TodoList, error) {
+	var l TodoList
+	err := row.Scan(&l.ID, &l.OwnerID, &l.Name, &l.CreatedAt, &l.Role, &l.OpenCount, &l.CompletedCount)
+	return l, translateError(err)
+}
+
+func (r *PostgresRepository) ListLists(ctx context.Context) ([]TodoList, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+listColumns+listGroupBy+` ORDER BY lists.name, lists.id`,
+		owner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	lists := []TodoList{}
+	for rows.Next() {
+		l, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, translateError(rows.Err())
+}
+
+func (r *PostgresRepository) CreateList(ctx context.Context, name string) (TodoList, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	// The creator is the list's first owner.
+	l := TodoList{Role: RoleOwner}
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`WITH list AS (
+			     INSERT INTO lists (owner_id, name, created_at) VALUES ($1, $2, NOW())
+			     RETURNING id, owner_id, name, created_at
+			 ), member AS (
+			     INSERT INTO list_members (list_id, user_id, role, joined_at)
+			     SELECT id, owner_id, $3, created_at FROM list
+			 )
+			 SELECT id, owner_id, name, created_at FROM list`,
+			owner, name, RoleOwner,
+		).Scan(&l.ID, &l.OwnerID, &l.Name, &l.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newListEvent(owner, EventListCreate, l))
+	})
+
+	return l, translateError(err)
+}
+
+func (r *PostgresRepository) GetList(ctx context.Context, id int) (TodoList, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return scanList(r.db(ctx).QueryRow(ctx,
+		`SELECT `+listColumns+` WHERE lists.id=$2`+listGroupBy,
+		owner, id,
+	))
+}
+
+func (r *PostgresRepository) RenameList(ctx context.Context, id int, name string) (TodoList, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return TodoList{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var l TodoList
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE lists SET name=$1
+			 WHERE id=$2 AND EXISTS (SELECT 1 FROM list_members WHERE list_id=$2 AND user_id=$3)`,
+			name, id, owner,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		l, err = scanList(tx.QueryRow(ctx,
+			`SELECT `+listColumns+` WHERE lists.id=$2`+listGroupBy,
+			owner, id,
+		))
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newListEvent(owner, EventListUpdate, l))
+	})
+
+	return l, translateError(err)
+}
+
+// DeleteList removes the list. Its todos are deleted with it if
+// deleteTodos is set and moved to the inbox otherwise.
+func (r *PostgresRepository) DeleteList(ctx context.Context, id int, deleteTodos bool) error {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		// Lock the list first so its todos are only deleted if the caller
+		// is a member.
+		l := TodoList{ID: id}
+		err := tx.QueryRow(ctx,
+			`SELECT owner_id, name FROM lists
+			 WHERE id=$1 AND EXISTS (SELECT 1 FROM list_members WHERE list_id=$1 AND user_id=$2)
+			 FOR UPDATE`,
+			id, owner,
+		).Scan(&l.OwnerID, &l.Name)
+		if err != nil {
+			return err
+		}
+		if deleteTodos {
+			// The todos and their subtasks go to the trash, and the
+			// foreign key below moves them to their owners' inboxes, so
+			// they can be restored from there.
+			rows, err := tx.Query(ctx,
+				`WITH RECURSIVE subtree AS (
+				     SELECT id FROM todos WHERE list_id=$1 AND deleted_at IS NULL
+				     UNION
+				     SELECT todos.id FROM todos JOIN subtree ON todos.parent_id = subtree.id
+				     WHERE todos.deleted_at IS NULL AND `+editableBy("$2")+`
+				 )
+				 UPDATE todos SET deleted_at = NOW(), version = version + 1 WHERE id IN (SELECT id FROM subtree)
+				 RETURNING `+todoColumns,
+				id, owner,
+			)
+			if err != nil {
+				return err
+			}
+			trashed, err := scanTodos(rows)
+			if err != nil {
+				return err
+			}
+			err = recordEvents(ctx, tx, EventDelete, trashed, func(t Todo) Todo {
+				t.DeletedAt = nil
+				t.Version--
+				return t
+			})
+			if err != nil {
+				return err
+			}
+		}
+		// The foreign key would move the list's todos to the inbox, but
+		// unrecorded.
+		rows, err := tx.Query(ctx,
+			`UPDATE todos SET list_id = NULL, version = version + 1 WHERE list_id=$1
+			 RETURNING `+todoColumns,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		moved, err := scanTodos(rows)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(moved, func(a, b Todo) int { return cmp.Compare(a.ID, b.ID) })
+		err = recordEvents(ctx, tx, EventUpdate, moved, func(t Todo) Todo {
+			t.ListID = &id
+			t.Version--
+			return t
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM lists WHERE id=$1`, id); err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newListEvent(owner, EventListDelete, l))
+	})
+
+	return translateError(err)
+}
@@ -0,0 +1,362 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// memberColumns selects a Member from list_members joined with users.
+const memberColumns = `list_members.list_id, list_members.user_id, users.email, list_members.role, list_members.joined_at
+	FROM list_members JOIN users ON users.id = list_members.user_id`
+
+func scanMember(row pgx.Row) (Member, error) {
+	var m Member
+	err := row.Scan(&m.ListID, &m.UserID, &m.Email, &m.Role, &m.JoinedAt)
+	return m, translateError(err)
+}
+
+// invitationColumns selects an Invitation from list_invitations joined
+// with lists.
+const invitationColumns = `list_invitations.id, list_invitations.list_id, lists.name, list_invitations.email,
+	list_invitations.role, COALESCE(list_invitations.invited_by, 0), list_invitations.created_at
+	FROM list_invitations JOIN lists ON lists.id = list_invitations.list_id`
+
+func scanInvitation(row pgx.Row) (Invitation, error) {
+	var inv Invitation
+	err := row.Scan(&inv.ID, &inv.ListID, &inv.ListName, &inv.Email, &inv.Role, &inv.InvitedBy, &inv.CreatedAt)
+	return inv, translateError(err)
+}
+
+func (r *PostgresRepository) ListRole(ctx context.Context, listID int) (Role, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var role Role
+	err = r.db(ctx).QueryRow(ctx,
+		`SELECT role FROM list_members WHERE list_id=$1 AND user_id=$2`,
+		listID, user,
+	).Scan(&role)
+
+	return role, translateError(err)
+}
+
+func (r *PostgresRepository) ListMembers(ctx context.Context, listID int) ([]Member, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT `+memberColumns+`
+		 WHERE list_members.list_id=$1
+		   AND EXISTS (SELECT 1 FROM list_members me WHERE me.list_id=$1 AND me.user_id=$2)
+		 ORDER BY list_members.joined_at, list_members.user_id`,
+		listID, user,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	members := []Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, translateError(err)
+	}
+	// Members always include the caller, so no rows means no access.
+	if len(members) == 0 {
+		return nil, ErrNotFound
+	}
+	return members, nil
+}
+
+// SetMemberRole changes a member's role. The list row is locked so that
+// concurrent changes cannot demote its last two owners at once.
+func (r *PostgresRepository) SetMemberRole(ctx context.Context, listID, userID int, role Role) (Member, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Member{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var m Member
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		if err := lockMemberList(ctx, tx, listID, user); err != nil {
+			return err
+		}
+		if role != RoleOwner {
+			if err := checkLastOwner(ctx, tx, listID, userID); err != nil {
+				return err
+			}
+		}
+		tag, err := tx.Exec(ctx,
+			`UPDATE list_members SET role=$1 WHERE list_id=$2 AND user_id=$3`,
+			role, listID, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		m, err = scanMember(tx.QueryRow(ctx,
+			`SELECT `+memberColumns+` WHERE list_members.list_id=$1 AND list_members.user_id=$2`,
+			listID, userID,
+		))
+		return err
+	})
+
+	return m, translateError(err)
+}
+
+func (r *PostgresRepository) RemoveMember(ctx context.Context, listID, userID int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		if err := lockMemberList(ctx, tx, listID, user); err != nil {
+			return err
+		}
+		if err := checkLastOwner(ctx, tx, listID, userID); err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx,
+			`DELETE FROM list_members WHERE list_id=$1 AND user_id=$2`,
+			listID, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+
+	return translateError(err)
+}
+
+// lockMemberList locks a list the user is a member of, serializing
+// membership changes to it.
+func lockMemberList(ctx context.Context, tx pgx.Tx, listID, user int) error {
+	tag, err := tx.Exec(ctx,
+		`SELECT 1 FROM lists
+		 WHERE id=$1 AND EXISTS (SELECT 1 FROM list_members WHERE list_id=$1 AND user_id=$2)
+		 FOR UPDATE`,
+		listID, user,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// checkLastOwner fails with ErrConflict if userID is the list's only
+// owner, so they can neither leave nor be demoted.
+func checkLastOwner(ctx context.Context, tx pgx.Tx, listID, userID int) error {
+	var last bool
+	err := tx.QueryRow(ctx,
+		`SELECT COALESCE(bool_and(user_id = $2), false) FROM list_members WHERE list_id=$1 AND role=$3`,
+		listID, userID, RoleOwner,
+	).Scan(&last)
+	if err != nil {
+		return err
+	}
+	if last {
+		return fmt.Errorf("%w: list %d must keep at least one owner", ErrConflict, listID)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) CreateInvitation(ctx context.Context, inv Invitation) (Invitation, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var member bool
+	err = r.db(ctx).QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM list_members JOIN users ON users.id = list_members.user_id
+		                WHERE list_members.list_id=$1 AND users.email=$2)`,
+		inv.ListID, inv.Email,
+	).Scan(&member)
+	if err != nil {
+		return Invitation{}, translateError(err)
+	}
+	if member {
+		return Invitation{}, fmt.Errorf("%w: %s is already a member of list %d", ErrConflict, inv.Email, inv.ListID)
+	}
+
+	// The unique (list_id, email) constraint turns a repeated invitation
+	// into ErrConflict.
+	var id int
+	err = r.db(ctx).QueryRow(ctx,
+		`INSERT INTO list_invitations (list_id, email, role, invited_by, created_at)
+		 SELECT $1, $2, $3, $4, NOW()
+		 WHERE EXISTS (SELECT 1 FROM list_members WHERE list_id=$1 AND user_id=$4)
+		 RETURNING id`,
+		inv.ListID, inv.Email, inv.Role, user,
+	).Scan(&id)
+	if err != nil {
+		return Invitation{}, translateError(err)
+	}
+
+	return scanInvitation(r.db(ctx).QueryRow(ctx,
+		`SELECT `+invitationColumns+` WHERE list_invitations.id=$1`,
+		id,
+	))
+}
+
+func (r *PostgresRepository) ListInvitations(ctx context.Context) ([]Invitation, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryInvitations(ctx,
+		`SELECT `+invitationColumns+`
+		 WHERE list_invitations.email = (SELECT email FROM users WHERE id=$1)
+		 ORDER BY list_invitations.created_at, list_invitations.id`,
+		user,
+	)
+}
+
+func (r *PostgresRepository) ListSentInvitations(ctx context.Context, listID int) ([]Invitation, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryInvitations(ctx,
+		`SELECT `+invitationColumns+`
+		 WHERE list_invitations.list_id=$1
+		   AND EXISTS (SELECT 1 FROM list_members WHERE list_id=$1 AND user_id=$2)
+		 ORDER BY list_invitations.created_at, list_invitations.id`,
+		listID, user,
+	)
+}
+
+func (r *PostgresRepository) queryInvitations(ctx context.Context, query string, args ...any) ([]Invitation, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	invitations := []Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, translateError(rows.Err())
+}
+
+// invitationVisible is a condition on list_invitations that holds for
+// invitations addressed to the user in parameter $2 or to lists they are
+// a member of.
+const invitationVisible = `(list_invitations.email = (SELECT email FROM users WHERE id=$2)
+	 OR list_invitations.list_id IN (SELECT list_id FROM list_members WHERE user_id=$2))`
+
+func (r *PostgresRepository) GetInvitation(ctx context.Context, id int) (Invitation, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Invitation{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return scanInvitation(r.db(ctx).QueryRow(ctx,
+		`SELECT `+invitationColumns+` WHERE list_invitations.id=$1 AND `+invitationVisible,
+		id, user,
+	))
+}
+
+func (r *PostgresRepository) AcceptInvitation(ctx context.Context, id int) (Member, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return Member{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var m Member
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		var listID int
+		var role Role
+		err := tx.QueryRow(ctx,
+			`DELETE FROM list_invitations
+			 WHERE id=$1 AND email = (SELECT email FROM users WHERE id=$2)
+			 RETURNING list_id, role`,
+			id, user,
+		).Scan(&listID, &role)
+		if err != nil {
+			return err
+		}
+		// The user may have been made a member since they were invited;
+		// keep the role they have.
+		_, err = tx.Exec(ctx,
+			`INSERT INTO list_members (list_id, user_id, role, joined_at) VALUES ($1, $2, $3, NOW())
+			 ON CONFLICT (list_id, user_id) DO NOTHING`,
+			listID, user, role,
+		)
+		if err != nil {
+			return err
+		}
+		m, err = scanMember(tx.QueryRow(ctx,
+			`SELECT `+memberColumns+` WHERE list_members.list_id=$1 AND list_members.user_id=$2`,
+			listID, user,
+		))
+		return err
+	})
+
+	return m, translateError(err)
+}
+
+func (r *PostgresRepository) DeleteInvitation(ctx context.Context, id int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tag, err := r.db(ctx).Exec(ctx,
+		`DELETE FROM list_invitations WHERE id=$1 AND `+invitationVisible,
+		id, user,
+	)
+	if err != nil {
+		return translateError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package todo
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// eventsChannel is the channel on which PostgresRepository notifies the
+// processes sharing its database of every event it records. The payload
+// is the event id.
+const eventsChannel = "todo_events"
+
+// catchUpWindow is how many event ids below the latest one it published
+// EventListener reads again when it reconnects. Ids are taken in insert
+// order but committed in any order, so an event with a lower id may have
+// committed while it was away. The broker remembers more ids than this,
+// so it drops the events read twice.
+const catchUpWindow = 1000
+
+// Reconnection delays of EventListener, doubling from the first to the
+// last while the database stays unreachable.
+const (
+	listenMinBackoff = time.Second
+	listenMaxBackoff = time.Minute
+)
+
+// notifyEvent sends the notification for event id on eventsChannel. In a
+// transaction, it is delivered when the transaction commits and not at
+// all if it rolls back.
+func notifyEvent(ctx context.Context, db dbtx, id int64) error {
+	_, err := db.Exec(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, strconv.FormatInt(id, 10))
+	return err
+}
+
+// EventListener publishes to a broker the events recorded by every
+// process sharing a Postgres database, so that watchers see the changes
+// made through other processes too. The broker drops the events its own
+// process has already published.
+type EventListener struct {
+	repo   *PostgresRepository
+	broker *Broker
+
+	// last is the id of the latest event published, and started is set
+	// once it is known.
+	last    int64
+	started bool
+}
+
+func NewEventListener(repo *PostgresRepository, broker *Broker) *EventListener {
+	return &EventListener{repo: repo, broker: broker}
+}
+
+// Run listens for events until ctx is cancelled. When the connection is
+// lost it reconnects, backing off while that fails, and then publishes
+// the events recorded while it was away.
+func (l *EventListener) Run(ctx context.Context) {
+	delay := listenMinBackoff
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = listenMinBackoff
+		}
+		log.Printf("Event listener disconnected: %v; reconnecting in %s", err, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, listenMaxBackoff)
+	}
+}
+
+// listen publishes events from one connection until it fails. It
+// reports whether it got as far as listening.
+func (l *EventListener) listen(ctx context.Context) (connected bool, err error) {
+	pc, err := l.repo.DB.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// The connection keeps listening until it is closed, so it must not
+	// go back to the pool.
+	conn := pc.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+eventsChannel); err != nil {
+		return false, err
+	}
+	// Listen first, then catch up, so that nothing recorded in between
+	// is missed; the broker drops what comes twice.
+	if l.started {
+		err = l.publish(ctx, conn, `WHERE id > $1 ORDER BY id`, l.last-catchUpWindow)
+	} else {
+		err = conn.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM todo_events`).Scan(&l.last)
+	}
+	if err != nil {
+		return false, err
+	}
+	l.started = true
+
+	// A context that is already done takes the notifications the
+	// connection has received without waiting for more.
+	received, cancel := context.WithCancel(ctx)
+	cancel()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		// The notifications that arrived while the last batch was read
+		// are read together.
+		var ids []int64
+		for n != nil {
+			id, err := strconv.ParseInt(n.Payload, 10, 64)
+			if err != nil {
+				log.Printf("Ignoring notification %q on %s", n.Payload, n.Channel)
+			} else {
+				ids = append(ids, id)
+			}
+			n, _ = conn.WaitForNotification(received)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := l.publish(ctx, conn, `WHERE id = ANY($1) ORDER BY id`, ids); err != nil {
+			return true, err
+		}
+	}
+}
+
+// publish reads the events selected by where from the audit log and
+// publishes them.
+func (l *EventListener) publish(ctx context.Context, conn *pgx.Conn, where string, args ...any) error {
+	ctx, cancel := l.repo.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := conn.Query(ctx,
+		`SELECT `+eventColumns+` FROM todo_events `+where,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		events = append(events, e)
+		l.last = max(l.last, e.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	l.broker.Publish(events...)
+	return nil
+}
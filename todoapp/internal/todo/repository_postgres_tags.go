@@ -0,0 +1,186 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *PostgresRepository) ListTags(ctx context.Context) ([]Tag, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.db(ctx).Query(ctx,
+		`SELECT id, name, owner_id FROM tags WHERE owner_id=$1 ORDER BY name, id`,
+		owner,
+	)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.OwnerID); err != nil {
+			return nil, translateError(err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, translateError(rows.Err())
+}
+
+func (r *PostgresRepository) CreateTag(ctx context.Context, name string) (Tag, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Tag{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var t Tag
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`INSERT INTO tags (name, owner_id) VALUES ($1, $2) RETURNING id, name, owner_id`,
+			name, owner,
+		).Scan(&t.ID, &t.Name, &t.OwnerID)
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newTagEvent(owner, EventTagCreate, t))
+	})
+
+	return t, translateError(err)
+}
+
+func (r *PostgresRepository) RenameTag(ctx context.Context, id int, name string) (Tag, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Tag{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var t Tag
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`UPDATE tags SET name=$1 WHERE id=$2 AND owner_id=$3 RETURNING id, name, owner_id`,
+			name, id, owner,
+		).Scan(&t.ID, &t.Name, &t.OwnerID)
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newTagEvent(owner, EventTagUpdate, t))
+	})
+
+	return t, translateError(err)
+}
+
+// DeleteTag removes the tag and detaches it from every todo.
+func (r *PostgresRepository) DeleteTag(ctx context.Context, id int) error {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		var t Tag
+		err := tx.QueryRow(ctx,
+			`DELETE FROM tags WHERE id=$1 AND owner_id=$2 RETURNING id, name, owner_id`,
+			id, owner,
+		).Scan(&t.ID, &t.Name, &t.OwnerID)
+		if err != nil {
+			return err
+		}
+		return appendEvent(ctx, tx, newTagEvent(owner, EventTagDelete, t))
+	})
+
+	return translateError(err)
+}
+
+// AttachTag tags a todo. Attaching a tag twice is not an error, and
+// records no event.
+func (r *PostgresRepository) AttachTag(ctx context.Context, todoID, tagID int) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var t Todo
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		before, err := lockTodo(ctx, tx, todoID, owner)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx,
+			`INSERT INTO todo_tags (todo_id, tag_id)
+			 SELECT $1, id FROM tags WHERE id=$2 AND owner_id=$3
+			 ON CONFLICT DO NOTHING`,
+			todoID, tagID, owner,
+		)
+		if err != nil {
+			// A concurrent delete of the tag violates a foreign key.
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			var found bool
+			err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM tags WHERE id=$1 AND owner_id=$2)`, tagID, owner).Scan(&found)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return ErrNotFound
+			}
+			t = before
+			return nil
+		}
+		t, err = scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, todoID))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, EventUpdate, &before, &t)
+	})
+
+	return t, translateError(err)
+}
+
+// DetachTag removes a tag from a todo. It returns ErrNotFound if the todo
+// does not carry the tag.
+func (r *PostgresRepository) DetachTag(ctx context.Context, todoID, tagID int) (Todo, error) {
+	owner, err := currentUser(ctx)
+	if err != nil {
+		return Todo{}, err
+	}
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var t Todo
+	err = beginFunc(ctx, r.db(ctx), func(tx pgx.Tx) error {
+		before, err := lockTodo(ctx, tx, todoID, owner)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id=$1 AND tag_id=$2`, todoID, tagID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		t, err = scanTodo(tx.QueryRow(ctx, `SELECT `+todoColumns+` FROM todos WHERE id=$1`, todoID))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, EventUpdate, &before, &t)
+	})
+
+	return t, translateError(err)
+}
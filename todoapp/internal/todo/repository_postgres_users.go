@@ -0,0 +1,43 @@
+package todo
+
+import "context"
+
+func (r *PostgresRepository) CreateUser(ctx context.Context, email, passwordHash string) (User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var u User
+	err := r.db(ctx).QueryRow(ctx,
+		`INSERT INTO users (email, password_hash, created_at) VALUES ($1, $2, NOW())
+		 RETURNING id, email, password_hash, created_at`,
+		email, passwordHash,
+	).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+
+	return u, translateError(err)
+}
+
+func (r *PostgresRepository) UserByEmail(ctx context.Context, email string) (User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var u User
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT id, email, password_hash, created_at FROM users WHERE email=$1`,
+		email,
+	).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+
+	return u, translateError(err)
+}
+
+func (r *PostgresRepository) UserByID(ctx context.Context, id int) (User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var u User
+	err := r.db(ctx).QueryRow(ctx,
+		`SELECT id, email, password_hash, created_at FROM users WHERE id=$1`,
+		id,
+	).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+
+	return u, translateError(err)
+}
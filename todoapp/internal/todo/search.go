@@ -0,0 +1,139 @@
+package todo
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Search result limits.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// Markers placed around matched terms in SearchResult.Snippet.
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+)
+
+// SearchResult is a todo matched by a full-text search.
+type SearchResult struct {
+	Todo
+	// Rank orders results by relevance; higher is better.
+	Rank float64 `json:"rank" example:"0.0607927"`
+	// Snippet is the matching text, HTML-escaped, with matched terms
+	// wrapped in <mark> tags.
+	Snippet string `json:"snippet" example:"Buy <mark>groceries</mark>"`
+}
+
+// Placeholder selection markers for ts_headline. They cannot appear in
+// escaped HTML, so the snippet can be escaped before they are swapped for
+// the real highlight tags.
+const (
+	pgHighlightStart = "\x01"
+	pgHighlightStop  = "\x02"
+)
+
+// pgHeadlineOptions are the ts_headline options for search snippets.
+const pgHeadlineOptions = "StartSel=" + pgHighlightStart + ", StopSel=" + pgHighlightStop + ", HighlightAll=true"
+
+// escapeHeadline HTML-escapes a ts_headline result produced with
+// pgHeadlineOptions and replaces its markers with highlight tags.
+func escapeHeadline(s string) string {
+	s = html.EscapeString(s)
+	s = strings.ReplaceAll(s, pgHighlightStart, highlightStart)
+	return strings.ReplaceAll(s, pgHighlightStop, highlightStop)
+}
+
+func validateSearch(query string, limit int) error {
+	if strings.TrimSpace(query) == "" {
+		return validationError("search query is required")
+	}
+	if limit < 0 || limit > MaxSearchLimit {
+		return validationError("limit must be between 1 and %d", MaxSearchLimit)
+	}
+	return nil
+}
+
+func searchLimit(limit int) int {
+	if limit == 0 {
+		return DefaultSearchLimit
+	}
+	return limit
+}
+
+// splitWords splits s into lower-cased words.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// wordMatches is a crude stand-in for stemming: a word matches a search
+// term when one is a prefix of the other, so "paint" finds "painting".
+func wordMatches(word, term string) bool {
+	if len(word) < len(term) {
+		word, term = term, word
+	}
+	return word == term || (len(term) >= 3 && strings.HasPrefix(word, term))
+}
+
+// matchText scores text against the search terms for the in-memory
+// repository. Every term must match some word, mirroring the AND
+// semantics of websearch_to_tsquery. It returns ok=false on no match.
+func matchText(text string, terms []string) (rank float64, snippet string, ok bool) {
+	words := splitWords(text)
+	if len(words) == 0 {
+		return 0, "", false
+	}
+
+	hits := 0
+	for _, term := range terms {
+		found := false
+		for _, w := range words {
+			if wordMatches(w, term) {
+				found = true
+				hits++
+			}
+		}
+		if !found {
+			return 0, "", false
+		}
+	}
+
+	// Highlight matched words in the original text.
+	var b strings.Builder
+	var word strings.Builder
+	flush := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := word.String()
+		matched := false
+		for _, term := range terms {
+			if wordMatches(strings.ToLower(w), term) {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			b.WriteString(highlightStart + html.EscapeString(w) + highlightStop)
+		} else {
+			b.WriteString(html.EscapeString(w))
+		}
+		word.Reset()
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+
+	return float64(hits) / float64(len(words)), b.String(), true
+}
@@ -1,66 +1,1017 @@
 package todo
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
 
 type service struct {
-	repo Repository
+	repo   Repository
+	broker *Broker
 }
 
+// Service implements the todo operations on behalf of the user in the
+// context. Reads see everything the user can see (see Repository);
+// changes also require the user's role in the todo's list to allow them,
+// and fail with ErrForbidden otherwise.
+//
+// Update, Delete and Toggle take the version of the todo the caller last
+// saw and fail with ErrPreconditionFailed if it has changed since. A zero
+// version applies the change to whatever the current version is.
+//
+// The events that changes record in the audit log are published to the
+// broker once they are committed, for Watch to pass on.
 type Service interface {
-	List() ([]Todo, error)
-	Create(title string) (Todo, error)
-	Get(id int) (Todo, error)
-	Update(id int, title string, completed bool) (Todo, error)
-	Delete(id int) error
-	Toggle(id int) (Todo, error)
+	List(ctx context.Context, opts ListOptions) (Page, error)
+	Create(ctx context.Context, req CreateTodoRequest) (Todo, error)
+	Get(ctx context.Context, id int) (Todo, error)
+	Update(ctx context.Context, id int, version int64, req UpdateTodoRequest) (Todo, error)
+	Patch(ctx context.Context, id int, version int64, p Patch) (Todo, error)
+	Delete(ctx context.Context, id int, version int64) error
+	Trash(ctx context.Context, opts ListOptions) (Page, error)
+	Restore(ctx context.Context, id int) (Todo, error)
+	Toggle(ctx context.Context, id int, version int64, cascade bool) (Todo, error)
+	Batch(ctx context.Context, req BatchRequest) ([]BatchResult, error)
+	Export(ctx context.Context, f FileFormat, opts ListOptions, w io.Writer) error
+	Import(ctx context.Context, req ImportRequest, r io.Reader) ([]ImportResult, error)
+	Move(ctx context.Context, id int, req MoveRequest) (Todo, error)
+	Skip(ctx context.Context, id int) (Todo, error)
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	Overdue(ctx context.Context, opts ListOptions) (Page, error)
+	DueToday(ctx context.Context, loc *time.Location, opts ListOptions) (Page, error)
+	Children(ctx context.Context, id int, opts ListOptions) (Page, error)
+	Tree(ctx context.Context) ([]TodoNode, error)
+	History(ctx context.Context, id int, f EventFilter) (EventPage, error)
+	Events(ctx context.Context, f EventFilter) (EventPage, error)
+	Watch(ctx context.Context, after int64, send func(e Event, cursor int64) error) error
+
+	ListTags(ctx context.Context) ([]Tag, error)
+	CreateTag(ctx context.Context, req TagRequest) (Tag, error)
+	RenameTag(ctx context.Context, id int, req TagRequest) (Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+	AttachTag(ctx context.Context, todoID int, req AttachTagRequest) (Todo, error)
+	DetachTag(ctx context.Context, todoID, tagID int) (Todo, error)
+
+	ListLists(ctx context.Context) ([]TodoList, error)
+	CreateList(ctx context.Context, req ListRequest) (TodoList, error)
+	GetList(ctx context.Context, id int) (TodoList, error)
+	RenameList(ctx context.Context, id int, req ListRequest) (TodoList, error)
+	DeleteList(ctx context.Context, id int, deleteTodos bool) error
+
+	ListMembers(ctx context.Context, listID int) ([]Member, error)
+	UpdateMember(ctx context.Context, listID, userID int, req MemberRequest) (Member, error)
+	RemoveMember(ctx context.Context, listID, userID int) error
+	Invite(ctx context.Context, listID int, req InvitationRequest) (Invitation, error)
+	ListInvitations(ctx context.Context) ([]Invitation, error)
+	ListSentInvitations(ctx context.Context, listID int) ([]Invitation, error)
+	AcceptInvitation(ctx context.Context, id int) (Member, error)
+	DeleteInvitation(ctx context.Context, id int) error
+}
+
+func NewService(repo Repository, broker *Broker) Service {
+	return &service{repo: repo, broker: broker}
+}
+
+func (s *service) List(ctx context.Context, opts ListOptions) (Page, error) {
+	if err := opts.Validate(); err != nil {
+		return Page{}, err
+	}
+	return s.repo.List(ctx, opts)
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+func (s *service) Create(ctx context.Context, req CreateTodoRequest) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		return s.create(ctx, Todo{
+			Title:      req.Title,
+			DueAt:      req.DueAt,
+			RemindAt:   req.RemindAt,
+			Priority:   req.Priority,
+			ListID:     req.ListID,
+			ParentID:   req.ParentID,
+			Recurrence: req.Recurrence,
+		})
+	})
 }
 
-func (s *service) List() ([]Todo, error) {
-	todos, err := s.repo.List()
+// create checks and stores a new todo. Only imports set its completion
+// state and creation time.
+func (s *service) create(ctx context.Context, t Todo) (Todo, error) {
+	if err := validateTitle(t.Title); err != nil {
+		return Todo{}, err
+	}
+	if err := validatePriority(t.Priority); err != nil {
+		return Todo{}, err
+	}
+	if err := s.checkList(ctx, t.ListID); err != nil {
+		return Todo{}, err
+	}
+	if err := s.authorize(ctx, t.ListID, RoleEditor); err != nil {
+		return Todo{}, err
+	}
+	if err := s.checkParent(ctx, 0, t.ParentID); err != nil {
+		return Todo{}, err
+	}
+	rule, err := normalizeRecurrence(t.Recurrence)
 	if err != nil {
-		return nil, err
+		return Todo{}, err
 	}
-	return todos, nil
+	t.Recurrence = rule
+	return s.repo.Create(ctx, t)
 }
 
-func (s *service) Create(title string) (Todo, error) {
-	t, err := s.repo.Create(title)
+func (s *service) Get(ctx context.Context, id int) (Todo, error) {
+	t, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return Todo{}, err
 	}
 	return t, nil
 }
 
-func (s *service) Get(id int) (Todo, error) {
-	t, err := s.repo.Get(id)
+// maxUpdateAttempts bounds how often Update reapplies a request without
+// a version when the todo keeps changing underneath it.
+const maxUpdateAttempts = 3
+
+func (s *service) Update(ctx context.Context, id int, version int64, req UpdateTodoRequest) (Todo, error) {
+	return s.update(ctx, id, version, func(Todo) (UpdateTodoRequest, error) {
+		return req, nil
+	})
+}
+
+// Patch applies a merge patch or JSON Patch to a todo. The patch must
+// leave the read-only fields as they are, and is applied entirely or not
+// at all.
+func (s *service) Patch(ctx context.Context, id int, version int64, p Patch) (Todo, error) {
+	if err := p.validate(); err != nil {
+		return Todo{}, err
+	}
+	return s.update(ctx, id, version, p.changes)
+}
+
+// update reads a todo, asks changes what to change about it and writes
+// it back conditional on the version it read, or on version if that is
+// set.
+func (s *service) update(ctx context.Context, id int, version int64, changes func(Todo) (UpdateTodoRequest, error)) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		for attempt := 1; ; attempt++ {
+			t, err := s.updateOnce(ctx, id, version, changes)
+			// Without a version from the caller, the changes apply to the
+			// todo as updateOnce read it; if it changed before the write,
+			// read it again rather than overwrite the other change.
+			if version == 0 && errors.Is(err, ErrPreconditionFailed) && attempt < maxUpdateAttempts {
+				continue
+			}
+			return t, err
+		}
+	})
+}
+
+func (s *service) updateOnce(ctx context.Context, id int, version int64, changes func(Todo) (UpdateTodoRequest, error)) (Todo, error) {
+	t, err := s.editable(ctx, id)
+	if err != nil {
+		return Todo{}, err
+	}
+	if version != 0 && t.Version != version {
+		return Todo{}, staleVersion(id, version)
+	}
+	req, err := changes(t)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	wasCompleted := t.Completed
+
+	if req.Title != nil {
+		t.Title = *req.Title
+	}
+	if req.Completed != nil {
+		t.Completed = *req.Completed
+	}
+	if req.DueAt.Set {
+		t.DueAt = req.DueAt.Value
+	}
+	if req.RemindAt.Set {
+		t.RemindAt = req.RemindAt.Value
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if req.ListID.Set {
+		t.ListID = req.ListID.Value
+		if err := s.checkList(ctx, t.ListID); err != nil {
+			return Todo{}, err
+		}
+		if err := s.authorize(ctx, t.ListID, RoleEditor); err != nil {
+			return Todo{}, err
+		}
+	}
+	if req.ParentID.Set {
+		t.ParentID = req.ParentID.Value
+		if err := s.checkParent(ctx, id, t.ParentID); err != nil {
+			return Todo{}, err
+		}
+	}
+	if req.Recurrence != nil {
+		if t.Recurrence, err = normalizeRecurrence(*req.Recurrence); err != nil {
+			return Todo{}, err
+		}
+	}
+	if err := validateTitle(t.Title); err != nil {
+		return Todo{}, err
+	}
+	if err := validatePriority(t.Priority); err != nil {
+		return Todo{}, err
+	}
+
+	// Only a change of completion moves completed_at.
+	if t.Completed != wasCompleted {
+		t.CompletedAt = nil
+		if t.Completed {
+			now := time.Now()
+			t.CompletedAt = &now
+		}
+	}
+	t, err = s.repo.Update(ctx, t)
 	if err != nil {
 		return Todo{}, err
 	}
 	return t, nil
 }
 
-func (s *service) Update(id int, title string, completed bool) (Todo, error) {
-	var completedAt *time.Time
-	if completed {
-		t := time.Now()
-		completedAt = &t
+func (s *service) Delete(ctx context.Context, id int, version int64) error {
+	_, err := publishing(ctx, s.broker, func(ctx context.Context) (struct{}, error) {
+		if _, err := s.editable(ctx, id); err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, s.repo.Delete(ctx, id, version)
+	})
+	return err
+}
+
+// Trash lists the todos in the trash.
+func (s *service) Trash(ctx context.Context, opts ListOptions) (Page, error) {
+	opts.Trashed = true
+	return s.List(ctx, opts)
+}
+
+// Restore takes a todo out of the trash, with the subtasks that were
+// deleted along with it.
+func (s *service) Restore(ctx context.Context, id int) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		t, err := s.repo.GetTrashed(ctx, id)
+		if err != nil {
+			return Todo{}, err
+		}
+		if err := s.authorize(ctx, t.ListID, RoleEditor); err != nil {
+			return Todo{}, err
+		}
+		return s.repo.Restore(ctx, id)
+	})
+}
+
+// Toggle flips a todo's completion state. With cascade, completing a
+// todo also completes all of its subtasks; reopening one never reopens
+// them.
+func (s *service) Toggle(ctx context.Context, id int, version int64, cascade bool) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		if _, err := s.editable(ctx, id); err != nil {
+			return Todo{}, err
+		}
+		return s.repo.Toggle(ctx, id, version, cascade)
+	})
+}
+
+// Batch applies the operations of req in order in one transaction and
+// returns one result per operation. In all-or-nothing mode the first
+// failure rolls the transaction back and every other operation's result
+// fails with ErrAborted; in best-effort mode each operation runs in a
+// savepoint, so a failure only undoes that operation. The error is only
+// set if the batch as a whole is invalid or could not be committed.
+func (s *service) Batch(ctx context.Context, req BatchRequest) ([]BatchResult, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) ([]BatchResult, error) {
+		return s.batch(ctx, req)
+	})
+}
+
+func (s *service) batch(ctx context.Context, req BatchRequest) ([]BatchResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = BatchResult{Op: op.Op, ID: op.ID}
+	}
+
+	if req.Mode == BatchBestEffort {
+		err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+			for i, op := range req.Operations {
+				err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+					results[i] = s.apply(ctx, op)
+					return results[i].Err
+				})
+				if err != nil && results[i].Err == nil {
+					results[i].Err = err
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		for i, op := range req.Operations {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if failed < 0 {
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+	for i, op := range req.Operations {
+		if i != failed {
+			err := fmt.Errorf("%w: operations[%d] failed", ErrAborted, failed)
+			results[i] = BatchResult{Op: op.Op, ID: op.ID, Err: err}
+		}
+	}
+	return results, nil
+}
+
+// apply runs one batch operation with the checks of the matching
+// Service method.
+func (s *service) apply(ctx context.Context, op BatchOperation) BatchResult {
+	res := BatchResult{Op: op.Op, ID: op.ID}
+	var t Todo
+	switch op.Op {
+	case BatchCreate:
+		if op.Create == nil {
+			res.Err = validationError("create needs a create object")
+			return res
+		}
+		t, res.Err = s.Create(ctx, *op.Create)
+	case BatchUpdate:
+		if op.Update == nil {
+			res.Err = validationError("update needs an update object")
+			return res
+		}
+		t, res.Err = s.Update(ctx, op.ID, op.Version, *op.Update)
+	case BatchToggle:
+		t, res.Err = s.Toggle(ctx, op.ID, op.Version, op.Cascade)
+	case BatchDelete:
+		res.Err = s.Delete(ctx, op.ID, op.Version)
+		return res
+	default:
+		res.Err = validationError("op must be create, update, toggle or delete")
+		return res
+	}
+	if res.Err == nil {
+		res.ID = t.ID
+		res.Todo = &t
+	}
+	return res
+}
+
+// Export writes the todos matching opts to w in format f, in the order
+// opts asks for, one page at a time. opts.Limit and opts.PageToken are
+// ignored. Nothing is written if the first page cannot be read.
+func (s *service) Export(ctx context.Context, f FileFormat, opts ListOptions, w io.Writer) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	opts.Limit, opts.PageToken = MaxPageSize, ""
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	enc := newTodoWriter(f, w)
+	for {
+		page, err := s.repo.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for _, t := range page.Todos {
+			if err := enc.Write(t); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			return enc.Close()
+		}
+		opts.PageToken = page.NextPageToken
+	}
+}
+
+// Import reads todos in req.Format from r and creates them in batches
+// of importBatchSize rows, each saved in a transaction of its own, which
+// a dry run rolls back. It returns a result per row. Rows that fail the
+// checks of Create are rejected. Rows with the title and creation time
+// of a todo the user can already see, or of an earlier row, are skipped.
+// A parent_id refers to the id of an earlier row of the file. The error
+// is only set if the file cannot be read or a batch cannot be saved; the
+// rest of the file is then not imported, and the results are those of
+// the batches saved before.
+func (s *service) Import(ctx context.Context, req ImportRequest, r io.Reader) ([]ImportResult, error) {
+	if err := req.Format.validate(); err != nil {
+		return nil, err
+	}
+
+	dec := newTodoReader(req.Format, r)
+	var imp *importer
+	var results []ImportResult
+	for {
+		// Each batch is read before its transaction starts, so that a
+		// slow upload holds no locks.
+		rows, last, err := readBatch(dec)
+		if err != nil {
+			return results, err
+		}
+		if len(rows) > 0 {
+			if imp == nil {
+				if imp, err = newImporter(ctx, s); err != nil {
+					return nil, err
+				}
+			}
+			batch, err := publishing(ctx, s.broker, func(ctx context.Context) ([]ImportResult, error) {
+				return s.importBatch(ctx, imp, rows, req.DryRun)
+			})
+			if err != nil {
+				return results, err
+			}
+			results = append(results, batch...)
+		}
+		if last {
+			return results, nil
+		}
 	}
-	t, err := s.repo.Update(id, title, completed, completedAt)
+}
+
+// importBatch saves rows in one transaction.
+func (s *service) importBatch(ctx context.Context, imp *importer, rows []importRow, dryRun bool) ([]ImportResult, error) {
+	var results []ImportResult
+	err := s.repo.WithinTx(ctx, func(ctx context.Context) error {
+		for _, row := range rows {
+			res, err := imp.add(ctx, row)
+			if err != nil {
+				return err
+			}
+			results = append(results, res)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	imp.endBatch(err == nil)
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Children lists the direct subtasks of a todo.
+func (s *service) Children(ctx context.Context, id int, opts ListOptions) (Page, error) {
+	if _, err := s.repo.Get(ctx, id); err != nil {
+		return Page{}, err
+	}
+	opts.ParentID = &id
+	opts.TopLevel = false
+	return s.List(ctx, opts)
+}
+
+// Tree returns every todo nested under its parent, siblings in position
+// order.
+func (s *service) Tree(ctx context.Context) ([]TodoNode, error) {
+	opts := ListOptions{Limit: MaxPageSize, Sort: SortByPosition}
+	var todos []Todo
+	for {
+		page, err := s.repo.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Todos...)
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	return buildTree(todos), nil
+}
+
+// History lists the changes made to a todo, newest first. It works for
+// todos in the trash as well.
+func (s *service) History(ctx context.Context, id int, f EventFilter) (EventPage, error) {
+	if _, err := s.repo.Get(ctx, id); errors.Is(err, ErrNotFound) {
+		if _, err := s.repo.GetTrashed(ctx, id); err != nil {
+			return EventPage{}, err
+		}
+	} else if err != nil {
+		return EventPage{}, err
+	}
+	f.TodoID = &id
+	return s.Events(ctx, f)
+}
+
+// Events lists the audit log across every todo the user can see, newest
+// first.
+func (s *service) Events(ctx context.Context, f EventFilter) (EventPage, error) {
+	if err := f.Validate(); err != nil {
+		return EventPage{}, err
+	}
+	return s.repo.ListEvents(ctx, f)
+}
+
+// Watch passes the events the user can see to send as their changes are
+// committed, until ctx is done, send fails or the broker closes. Along
+// with each event send gets a cursor, which a later Watch resumes from
+// when given as after: a nonzero after first replays the events after it
+// from the audit log, or sends an EventReset if there are more than
+// maxReplay. A watcher that falls behind catches up the same way.
+//
+// The cursor is the id up to which every event is passed on or not
+// visible, so a Watch resumed from it may repeat some of the events sent
+// after it; the event id tells them apart. An event that has not arrived
+// within commitGrace of a later one is given up on: the cursor moves past
+// it, but it is still sent if it arrives.
+func (s *service) Watch(ctx context.Context, after int64, send func(e Event, cursor int64) error) error {
+	if after < 0 {
+		return validationError("event id must not be negative")
+	}
+	cur := newWatchCursor(after)
+	replay := after != 0
+	for {
+		events, unsubscribe := s.broker.subscribe()
+		// Start from the latest event, so that a watcher that falls
+		// behind knows where to catch up from.
+		if !replay {
+			latest, err := s.repo.LatestEventID(ctx)
+			if err != nil {
+				unsubscribe()
+				return err
+			}
+			cur = newWatchCursor(latest)
+		}
+		err := s.watch(ctx, events, cur, replay, send)
+		unsubscribe()
+		if !errors.Is(err, errFellBehind) {
+			return err
+		}
+		replay = true
+	}
+}
+
+// errFellBehind reports a watcher that the broker dropped for falling
+// behind.
+var errFellBehind = errors.New("watcher fell behind")
+
+// watch replays the events after cur if replay is set, then passes on
+// the events of one subscription, moving cur along.
+func (s *service) watch(ctx context.Context, events <-chan Event, cur *watchCursor, replay bool, send func(Event, int64) error) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	// The subscription starts before the replay reads the audit log, so
+	// the events committed in between come from both.
+	if replay {
+		missed, err := s.repo.EventsAfter(ctx, cur.low, maxReplay+1)
+		if err != nil {
+			return err
+		}
+		if len(missed) > maxReplay {
+			latest, err := s.repo.LatestEventID(ctx)
+			if err != nil {
+				return err
+			}
+			*cur = *newWatchCursor(latest)
+			if err := send(Event{Action: EventReset, CreatedAt: time.Now()}, cur.low); err != nil {
+				return err
+			}
+			missed = nil
+		}
+		for _, e := range missed {
+			if cur.done(e.ID) {
+				continue
+			}
+			if err := send(e, cur.pass(e.ID, time.Now())); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				if s.broker.isClosed() {
+					return nil
+				}
+				return errFellBehind
+			}
+			if cur.done(e.ID) {
+				continue
+			}
+			visible, err := s.canWatch(ctx, user, e)
+			if err != nil {
+				return err
+			}
+			cursor := cur.pass(e.ID, time.Now())
+			if !visible {
+				continue
+			}
+			if err := send(e, cursor); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// canWatch reports whether user may see event e as it happens: whether
+// they made the change, or can access the todo as it was before or after
+// it, or the list or tag it is about.
+func (s *service) canWatch(ctx context.Context, user int, e Event) (bool, error) {
+	if e.ActorID == user {
+		return true, nil
+	}
+	for _, t := range []*Todo{e.Before, e.After, {OwnerID: e.ownerID, ListID: e.ListID}} {
+		if t == nil {
+			continue
+		}
+		if t.ListID == nil {
+			if t.OwnerID == user {
+				return true, nil
+			}
+			continue
+		}
+		_, err := s.repo.ListRole(ctx, *t.ListID)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// publishing runs fn with a context that collects the events recorded by
+// its changes, and publishes them if fn succeeds. Called by another
+// publishing call, it leaves publishing to the outer one, whose changes
+// may still roll back.
+func publishing[T any](ctx context.Context, broker *Broker, fn func(ctx context.Context) (T, error)) (T, error) {
+	if recorderFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	rec := &recorder{}
+	v, err := fn(context.WithValue(ctx, recorderKey{}, rec))
+	if err == nil {
+		broker.Publish(rec.events...)
+	}
+	return v, err
+}
+
+// Move places a todo immediately before or after another one in the
+// manual ordering.
+func (s *service) Move(ctx context.Context, id int, req MoveRequest) (Todo, error) {
+	anchorID, after, err := req.anchor(id)
 	if err != nil {
 		return Todo{}, err
 	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		if _, err := s.editable(ctx, id); err != nil {
+			return Todo{}, err
+		}
+		return s.repo.Move(ctx, id, anchorID, after)
+	})
+}
+
+// Skip moves an open recurring todo to its next occurrence without
+// completing it.
+func (s *service) Skip(ctx context.Context, id int) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		t, err := s.editable(ctx, id)
+		if err != nil {
+			return Todo{}, err
+		}
+		if t.Recurrence == "" {
+			return Todo{}, validationError("todo %d does not recur", id)
+		}
+		if t.Completed {
+			return Todo{}, validationError("todo %d is completed", id)
+		}
+		next, err := t.nextOccurrence(time.Now())
+		if err != nil {
+			return Todo{}, err
+		}
+		t.DueAt, t.RemindAt = next.DueAt, next.RemindAt
+		return s.repo.Update(ctx, t)
+	})
+}
+
+func (s *service) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if err := validateSearch(query, limit); err != nil {
+		return nil, err
+	}
+	return s.repo.Search(ctx, query, limit)
+}
+
+// Overdue lists open todos whose due date has passed, soonest due first
+// unless opts asks for another order.
+func (s *service) Overdue(ctx context.Context, opts ListOptions) (Page, error) {
+	now := time.Now()
+	incomplete := false
+	opts.Completed = &incomplete
+	opts.DueBefore = &now
+	if opts.Sort == "" {
+		opts.Sort = SortByDueAt
+	}
+	return s.List(ctx, opts)
+}
+
+// DueToday lists todos due during the current calendar day in loc.
+func (s *service) DueToday(ctx context.Context, loc *time.Location, opts ListOptions) (Page, error) {
+	y, m, d := time.Now().In(loc).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	// AddDate keeps the boundary at local midnight across DST changes.
+	end := start.AddDate(0, 0, 1)
+
+	// DueAfter is exclusive, so step back to include todos due exactly
+	// at midnight.
+	after := start.Add(-time.Nanosecond)
+	opts.DueAfter = &after
+	opts.DueBefore = &end
+	if opts.Sort == "" {
+		opts.Sort = SortByDueAt
+	}
+	return s.List(ctx, opts)
+}
+
+func (s *service) ListTags(ctx context.Context) ([]Tag, error) {
+	return s.repo.ListTags(ctx)
+}
+
+func (s *service) CreateTag(ctx context.Context, req TagRequest) (Tag, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return Tag{}, err
+	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (Tag, error) {
+		return s.repo.CreateTag(ctx, name)
+	})
+}
+
+func (s *service) RenameTag(ctx context.Context, id int, req TagRequest) (Tag, error) {
+	name, err := normalizeTagName(req.Name)
+	if err != nil {
+		return Tag{}, err
+	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (Tag, error) {
+		return s.repo.RenameTag(ctx, id, name)
+	})
+}
+
+func (s *service) DeleteTag(ctx context.Context, id int) error {
+	_, err := publishing(ctx, s.broker, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, s.repo.DeleteTag(ctx, id)
+	})
+	return err
+}
+
+func (s *service) AttachTag(ctx context.Context, todoID int, req AttachTagRequest) (Todo, error) {
+	if req.TagID == 0 {
+		return Todo{}, validationError("tag_id is required")
+	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		if _, err := s.editable(ctx, todoID); err != nil {
+			return Todo{}, err
+		}
+		return s.repo.AttachTag(ctx, todoID, req.TagID)
+	})
+}
+
+func (s *service) DetachTag(ctx context.Context, todoID, tagID int) (Todo, error) {
+	return publishing(ctx, s.broker, func(ctx context.Context) (Todo, error) {
+		if _, err := s.editable(ctx, todoID); err != nil {
+			return Todo{}, err
+		}
+		return s.repo.DetachTag(ctx, todoID, tagID)
+	})
+}
+
+func (s *service) ListLists(ctx context.Context) ([]TodoList, error) {
+	return s.repo.ListLists(ctx)
+}
+
+func (s *service) CreateList(ctx context.Context, req ListRequest) (TodoList, error) {
+	name, err := normalizeListName(req.Name)
+	if err != nil {
+		return TodoList{}, err
+	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (TodoList, error) {
+		return s.repo.CreateList(ctx, name)
+	})
+}
+
+func (s *service) GetList(ctx context.Context, id int) (TodoList, error) {
+	return s.repo.GetList(ctx, id)
+}
+
+func (s *service) RenameList(ctx context.Context, id int, req ListRequest) (TodoList, error) {
+	name, err := normalizeListName(req.Name)
+	if err != nil {
+		return TodoList{}, err
+	}
+	return publishing(ctx, s.broker, func(ctx context.Context) (TodoList, error) {
+		if err := s.authorize(ctx, &id, RoleOwner); err != nil {
+			return TodoList{}, err
+		}
+		return s.repo.RenameList(ctx, id, name)
+	})
+}
+
+func (s *service) DeleteList(ctx context.Context, id int, deleteTodos bool) error {
+	_, err := publishing(ctx, s.broker, func(ctx context.Context) (struct{}, error) {
+		if err := s.authorize(ctx, &id, RoleOwner); err != nil {
+			return struct{}{}, err
+		}
+		return struct{}{}, s.repo.DeleteList(ctx, id, deleteTodos)
+	})
+	return err
+}
+
+func (s *service) ListMembers(ctx context.Context, listID int) ([]Member, error) {
+	return s.repo.ListMembers(ctx, listID)
+}
+
+// UpdateMember changes a member's role. Only owners may change roles,
+// and a list always keeps at least one owner.
+func (s *service) UpdateMember(ctx context.Context, listID, userID int, req MemberRequest) (Member, error) {
+	if err := validateRole(req.Role); err != nil {
+		return Member{}, err
+	}
+	if err := s.authorize(ctx, &listID, RoleOwner); err != nil {
+		return Member{}, err
+	}
+	return s.repo.SetMemberRole(ctx, listID, userID, req.Role)
+}
+
+// RemoveMember removes a member from a list. Owners may remove anyone;
+// other members may only leave.
+func (s *service) RemoveMember(ctx context.Context, listID, userID int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if userID != user {
+		if err := s.authorize(ctx, &listID, RoleOwner); err != nil {
+			return err
+		}
+	}
+	return s.repo.RemoveMember(ctx, listID, userID)
+}
+
+// Invite offers membership of a list to the user with req.Email, who
+// need not have registered yet. Only owners may invite.
+func (s *service) Invite(ctx context.Context, listID int, req InvitationRequest) (Invitation, error) {
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return Invitation{}, err
+	}
+	if err := validateRole(req.Role); err != nil {
+		return Invitation{}, err
+	}
+	if err := s.authorize(ctx, &listID, RoleOwner); err != nil {
+		return Invitation{}, err
+	}
+	return s.repo.CreateInvitation(ctx, Invitation{ListID: listID, Email: email, Role: req.Role})
+}
+
+func (s *service) ListInvitations(ctx context.Context) ([]Invitation, error) {
+	return s.repo.ListInvitations(ctx)
+}
+
+func (s *service) ListSentInvitations(ctx context.Context, listID int) ([]Invitation, error) {
+	if err := s.authorize(ctx, &listID, RoleOwner); err != nil {
+		return nil, err
+	}
+	return s.repo.ListSentInvitations(ctx, listID)
+}
+
+func (s *service) AcceptInvitation(ctx context.Context, id int) (Member, error) {
+	return s.repo.AcceptInvitation(ctx, id)
+}
+
+// DeleteInvitation declines an invitation addressed to the user or, for
+// a list's owners, withdraws one.
+func (s *service) DeleteInvitation(ctx context.Context, id int) error {
+	inv, err := s.repo.GetInvitation(ctx, id)
+	if err != nil {
+		return err
+	}
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	u, err := s.repo.UserByID(ctx, user)
+	if err != nil {
+		return err
+	}
+	if inv.Email != u.Email {
+		if err := s.authorize(ctx, &inv.ListID, RoleOwner); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteInvitation(ctx, id)
+}
+
+// editable returns todo id if the user may change it.
+func (s *service) editable(ctx context.Context, id int) (Todo, error) {
+	t, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Todo{}, err
+	}
+	if err := s.authorize(ctx, t.ListID, RoleEditor); err != nil {
+		return Todo{}, err
+	}
 	return t, nil
 }
 
-func (s *service) Delete(id int) error {
-	return s.repo.Delete(id)
+// authorize checks that the user's role in list listID allows want. A
+// nil listID is the user's own inbox, where they may do anything.
+func (s *service) authorize(ctx context.Context, listID *int, want Role) error {
+	if listID == nil {
+		return nil
+	}
+	role, err := s.repo.ListRole(ctx, *listID)
+	if err != nil {
+		return err
+	}
+	if !role.allows(want) {
+		return fmt.Errorf("%w: %s role required in list %d", ErrForbidden, want, *listID)
+	}
+	return nil
+}
+
+// checkList reports a todo's reference to a missing list as a
+// validation error. A nil id is the inbox.
+func (s *service) checkList(ctx context.Context, id *int) error {
+	if id == nil {
+		return nil
+	}
+	_, err := s.repo.GetList(ctx, *id)
+	if errors.Is(err, ErrNotFound) {
+		return validationError("list %d does not exist", *id)
+	}
+	return err
+}
+
+// checkParent validates parentID as the parent of todo id, which is zero
+// for a todo being created. A nil parentID makes a top-level todo.
+func (s *service) checkParent(ctx context.Context, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return validationError("a todo cannot be its own parent")
+	}
+	ancestors, err := s.repo.Ancestors(ctx, *parentID)
+	if errors.Is(err, ErrNotFound) {
+		return validationError("parent todo %d does not exist", *parentID)
+	}
+	if err != nil {
+		return err
+	}
+	if id != 0 && slices.Contains(ancestors, id) {
+		return validationError("todo %d is a subtask of todo %d, so it cannot be its parent", *parentID, id)
+	}
+	if len(ancestors)+1 >= maxDepth {
+		return validationError("subtasks cannot be nested more than %d levels deep", maxDepth)
+	}
+	return nil
 }
 
-func (s *service) Toggle(id int) (Todo, error) {
-	t, err := s.repo.Toggle(id)
-	return t, err
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return validationError("title is required")
+	}
+	return nil
 }
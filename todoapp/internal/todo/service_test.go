@@ -0,0 +1,743 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T) (Service, *MemoryRepository) {
+	t.Helper()
+	repo := NewMemoryRepository()
+	return NewService(repo, NewBroker()), repo
+}
+
+// titles returns the titles of the todos the user in ctx lists with opts.
+func titles(t *testing.T, svc Service, ctx context.Context, opts ListOptions) []string {
+	t.Helper()
+	page, err := svc.List(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, todo := range page.Todos {
+		got = append(got, todo.Title)
+	}
+	return got
+}
+
+func TestServicePatch(t *testing.T) {
+	due := time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		patch   Patch
+		version int64
+		wantErr error
+		check   func(t *testing.T, got Todo)
+	}{
+		{
+			name:  "merge title",
+			patch: Patch{MergePatch, []byte(`{"title":"Buy oat milk"}`)},
+			check: func(t *testing.T, got Todo) {
+				if got.Title != "Buy oat milk" || got.DueAt == nil {
+					t.Errorf("got title %q, due %v; want only the title changed", got.Title, got.DueAt)
+				}
+			},
+		},
+		{
+			name:  "merge null clears",
+			patch: Patch{MergePatch, []byte(`{"due_at":null}`)},
+			check: func(t *testing.T, got Todo) {
+				if got.DueAt != nil {
+					t.Errorf("DueAt = %v, want nil", got.DueAt)
+				}
+			},
+		},
+		{
+			name:  "json patch",
+			patch: Patch{JSONPatch, []byte(`[{"op":"test","path":"/title","value":"Buy milk"},{"op":"replace","path":"/priority","value":3}]`)},
+			check: func(t *testing.T, got Todo) {
+				if got.Priority != PriorityHigh {
+					t.Errorf("Priority = %d, want %d", got.Priority, PriorityHigh)
+				}
+			},
+		},
+		{
+			name:    "json patch failed test",
+			patch:   Patch{JSONPatch, []byte(`[{"op":"test","path":"/title","value":"Buy bread"},{"op":"replace","path":"/priority","value":3}]`)},
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "read-only field",
+			patch:   Patch{MergePatch, []byte(`{"owner_id":9}`)},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "unknown format",
+			patch:   Patch{"application/json", []byte(`{"title":"x"}`)},
+			wantErr: ErrValidation,
+		},
+		{
+			name:    "stale version",
+			patch:   Patch{MergePatch, []byte(`{"title":"Buy oat milk"}`)},
+			version: 7,
+			wantErr: ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			created, err := svc.Create(ctx, CreateTodoRequest{Title: "Buy milk", DueAt: &due})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := svc.Patch(ctx, created.ID, tt.version, tt.patch)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Patch error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				unchanged, err := svc.Get(ctx, created.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if unchanged.Version != created.Version {
+					t.Errorf("failed patch changed the todo to %+v", unchanged)
+				}
+				return
+			}
+			if got.Version != created.Version+1 {
+				t.Errorf("Version = %d, want %d", got.Version, created.Version+1)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestServiceUpdateVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int64
+		wantErr error
+	}{
+		{"current", 1, nil},
+		{"any", 0, nil},
+		{"stale", 2, ErrPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			created, err := svc.Create(ctx, CreateTodoRequest{Title: "Buy milk"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			title := "Buy oat milk"
+			_, err = svc.Update(ctx, created.ID, tt.version, UpdateTodoRequest{Title: &title})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServiceBatch(t *testing.T) {
+	create := func(title string) BatchOperation {
+		return BatchOperation{Op: BatchCreate, Create: &CreateTodoRequest{Title: title}}
+	}
+	missing := BatchOperation{Op: BatchToggle, ID: 99}
+
+	tests := []struct {
+		name string
+		req  BatchRequest
+		// wantErrs are the errors of the results, and want the titles of
+		// the todos left.
+		wantErrs []error
+		want     []string
+	}{
+		{
+			name:     "all or nothing",
+			req:      BatchRequest{Operations: []BatchOperation{create("a"), create("b")}},
+			wantErrs: []error{nil, nil},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "all or nothing failure",
+			req:      BatchRequest{Operations: []BatchOperation{create("a"), missing, create("b")}},
+			wantErrs: []error{ErrAborted, ErrNotFound, ErrAborted},
+			want:     []string{},
+		},
+		{
+			name:     "best effort failure",
+			req:      BatchRequest{Mode: BatchBestEffort, Operations: []BatchOperation{create("a"), missing, create("b")}},
+			wantErrs: []error{nil, ErrNotFound, nil},
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "invalid operation",
+			req:      BatchRequest{Mode: BatchBestEffort, Operations: []BatchOperation{create("a"), {Op: BatchUpdate, ID: 1}}},
+			wantErrs: []error{nil, ErrValidation},
+			want:     []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+
+			results, err := svc.Batch(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != len(tt.wantErrs) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.wantErrs))
+			}
+			for i, res := range results {
+				if !errors.Is(res.Err, tt.wantErrs[i]) {
+					t.Errorf("results[%d].Err = %v, want %v", i, res.Err, tt.wantErrs[i])
+				}
+				if res.Err == nil && res.Todo == nil {
+					t.Errorf("results[%d] has no todo", i)
+				}
+			}
+			got := titles(t, svc, ctx, ListOptions{})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("titles = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceBatchInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  BatchRequest
+	}{
+		{"empty", BatchRequest{}},
+		{"unknown mode", BatchRequest{Mode: "some", Operations: []BatchOperation{{Op: BatchToggle, ID: 1}}}},
+		{"too large", BatchRequest{Operations: make([]BatchOperation, MaxBatchSize+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			if _, err := svc.Batch(ctx, tt.req); !errors.Is(err, ErrValidation) {
+				t.Errorf("Batch error = %v, want %v", err, ErrValidation)
+			}
+		})
+	}
+}
+
+func TestServiceSharing(t *testing.T) {
+	tests := []struct {
+		role          Role
+		wantUpdateErr error
+		wantInviteErr error
+	}{
+		{RoleViewer, ErrForbidden, ErrForbidden},
+		{RoleEditor, nil, ErrForbidden},
+		{RoleOwner, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.role), func(t *testing.T) {
+			svc, repo := newTestService(t)
+			alice := newUser(t, repo, "alice@example.com")
+			bob := newUser(t, repo, "bob@example.com")
+			carol := newUser(t, repo, "carol@example.com")
+
+			list, err := svc.CreateList(alice, ListRequest{Name: "Home"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			todo, err := svc.Create(alice, CreateTodoRequest{Title: "Buy milk", ListID: &list.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.Get(bob, todo.ID); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get before joining: error = %v, want %v", err, ErrNotFound)
+			}
+
+			inv, err := svc.Invite(alice, list.ID, InvitationRequest{Email: "bob@example.com", Role: tt.role})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.AcceptInvitation(carol, inv.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("AcceptInvitation by someone else: error = %v, want %v", err, ErrNotFound)
+			}
+			if _, err := svc.AcceptInvitation(bob, inv.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := svc.Get(bob, todo.ID); err != nil {
+				t.Errorf("Get after joining: %v", err)
+			}
+			title := "Buy oat milk"
+			_, err = svc.Update(bob, todo.ID, 0, UpdateTodoRequest{Title: &title})
+			if !errors.Is(err, tt.wantUpdateErr) {
+				t.Errorf("Update error = %v, want %v", err, tt.wantUpdateErr)
+			}
+			_, err = svc.Invite(bob, list.ID, InvitationRequest{Email: "carol@example.com", Role: RoleViewer})
+			if !errors.Is(err, tt.wantInviteErr) {
+				t.Errorf("Invite error = %v, want %v", err, tt.wantInviteErr)
+			}
+			if _, err := svc.Get(carol, todo.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get by a stranger: error = %v, want %v", err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestServiceMove(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      int
+		req     MoveRequest
+		want    []string
+		wantErr error
+	}{
+		{"before first", 3, MoveRequest{Before: ptr(1)}, []string{"c", "a", "b"}, nil},
+		{"after first", 3, MoveRequest{After: ptr(1)}, []string{"a", "c", "b"}, nil},
+		{"after last", 1, MoveRequest{After: ptr(3)}, []string{"b", "c", "a"}, nil},
+		{"no anchor", 1, MoveRequest{}, nil, ErrValidation},
+		{"itself", 1, MoveRequest{Before: ptr(1)}, nil, ErrValidation},
+		{"other list", 1, MoveRequest{Before: ptr(4)}, nil, ErrValidation},
+		{"missing anchor", 1, MoveRequest{Before: ptr(9)}, nil, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			list, err := svc.CreateList(ctx, ListRequest{Name: "Home"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, req := range []CreateTodoRequest{{Title: "a"}, {Title: "b"}, {Title: "c"}, {Title: "d", ListID: &list.ID}} {
+				if _, err := svc.Create(ctx, req); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err = svc.Move(ctx, tt.id, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Move error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got := titles(t, svc, ctx, ListOptions{Inbox: true, Sort: SortByPosition})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("inbox order = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceMoveRenumbers(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := newUser(t, repo, "alice@example.com")
+	a, err := svc.Create(ctx, CreateTodoRequest{Title: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := svc.Create(ctx, CreateTodoRequest{Title: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each move halves the gap before b, until the todos are renumbered.
+	want := []string{a.Title}
+	for i := range 15 {
+		todo, err := svc.Create(ctx, CreateTodoRequest{Title: fmt.Sprint(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Move(ctx, todo.ID, MoveRequest{Before: &b.ID}); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, todo.Title)
+	}
+	want = append(want, b.Title)
+
+	got := titles(t, svc, ctx, ListOptions{Sort: SortByPosition, Limit: 100})
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("order = %q, want %q", got, want)
+	}
+}
+
+func TestServiceUpdateListAppends(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := newUser(t, repo, "alice@example.com")
+	list, err := svc.CreateList(ctx, ListRequest{Name: "Home"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []CreateTodoRequest{{Title: "a"}, {Title: "b"}, {Title: "c"}, {Title: "x", ListID: &list.ID}} {
+		if _, err := svc.Create(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a goes to the end of the list, and back to the end of the inbox.
+	steps := []struct {
+		listID    *int
+		wantInbox []string
+		wantList  []string
+	}{
+		{&list.ID, []string{"b", "c"}, []string{"x", "a"}},
+		{nil, []string{"b", "c", "a"}, []string{"x"}},
+	}
+	for _, step := range steps {
+		if _, err := svc.Update(ctx, 1, 0, UpdateTodoRequest{ListID: NullableInt{Set: true, Value: step.listID}}); err != nil {
+			t.Fatal(err)
+		}
+		if got := titles(t, svc, ctx, ListOptions{Inbox: true, Sort: SortByPosition}); fmt.Sprint(got) != fmt.Sprint(step.wantInbox) {
+			t.Errorf("inbox = %q, want %q", got, step.wantInbox)
+		}
+		if got := titles(t, svc, ctx, ListOptions{ListID: &list.ID, Sort: SortByPosition}); fmt.Sprint(got) != fmt.Sprint(step.wantList) {
+			t.Errorf("list = %q, want %q", got, step.wantList)
+		}
+	}
+}
+
+// createTree creates todo a with subtask b, which has subtask c.
+func createTree(t *testing.T, svc Service, ctx context.Context) (a, b, c Todo) {
+	t.Helper()
+	var err error
+	if a, err = svc.Create(ctx, CreateTodoRequest{Title: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err = svc.Create(ctx, CreateTodoRequest{Title: "b", ParentID: &a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = svc.Create(ctx, CreateTodoRequest{Title: "c", ParentID: &b.ID}); err != nil {
+		t.Fatal(err)
+	}
+	return a, b, c
+}
+
+func TestServiceParent(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int
+		parentID *int
+		wantErr  error
+	}{
+		{"top level", 3, nil, nil},
+		{"other parent", 3, ptr(1), nil},
+		{"itself", 1, ptr(1), ErrValidation},
+		{"own subtask", 1, ptr(3), ErrValidation},
+		{"missing parent", 3, ptr(9), ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			createTree(t, svc, ctx)
+
+			got, err := svc.Update(ctx, tt.id, 0, UpdateTodoRequest{ParentID: NullableInt{Set: true, Value: tt.parentID}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if (got.ParentID == nil) != (tt.parentID == nil) || got.ParentID != nil && *got.ParentID != *tt.parentID {
+				t.Errorf("ParentID = %v, want %v", got.ParentID, tt.parentID)
+			}
+		})
+	}
+}
+
+func TestServiceToggleCascade(t *testing.T) {
+	tests := []struct {
+		cascade bool
+		// want are the completed todos after toggling a.
+		want []string
+	}{
+		{false, []string{"a"}},
+		{true, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint("cascade=", tt.cascade), func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			a, _, _ := createTree(t, svc, ctx)
+
+			if _, err := svc.Toggle(ctx, a.ID, 0, tt.cascade); err != nil {
+				t.Fatal(err)
+			}
+			completed := true
+			got := titles(t, svc, ctx, ListOptions{Completed: &completed})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("completed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceCascadeSkipsOthersSubtasks(t *testing.T) {
+	tests := []struct {
+		name string
+		// change changes todo id as bob.
+		change func(svc Service, bob context.Context, id int) error
+		// wantOpen and wantTrashed are the titles of alice's open and
+		// trashed todos after the change.
+		wantOpen    []string
+		wantTrashed []string
+	}{
+		{
+			name: "toggle",
+			change: func(svc Service, bob context.Context, id int) error {
+				_, err := svc.Toggle(bob, id, 0, true)
+				return err
+			},
+			wantOpen:    []string{"private"},
+			wantTrashed: []string{},
+		},
+		{
+			name: "delete",
+			change: func(svc Service, bob context.Context, id int) error {
+				return svc.Delete(bob, id, 0)
+			},
+			wantOpen:    []string{"private"},
+			wantTrashed: []string{"shared", "shared subtask"},
+		},
+		{
+			name: "delete and restore",
+			change: func(svc Service, bob context.Context, id int) error {
+				if err := svc.Delete(bob, id, 0); err != nil {
+					return err
+				}
+				_, err := svc.Restore(bob, id)
+				return err
+			},
+			wantOpen:    []string{"shared", "private", "shared subtask"},
+			wantTrashed: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			alice := newUser(t, repo, "alice@example.com")
+			bob := newUser(t, repo, "bob@example.com")
+			list, err := svc.CreateList(alice, ListRequest{Name: "Home"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			inv, err := svc.Invite(alice, list.ID, InvitationRequest{Email: "bob@example.com", Role: RoleEditor})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.AcceptInvitation(bob, inv.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			// alice files a subtask of a shared todo in her inbox.
+			shared, err := svc.Create(alice, CreateTodoRequest{Title: "shared", ListID: &list.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			private, err := svc.Create(alice, CreateTodoRequest{Title: "private", ParentID: &shared.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = svc.Create(alice, CreateTodoRequest{Title: "shared subtask", ListID: &list.ID, ParentID: &shared.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.change(svc, bob, shared.ID); err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(t, svc, alice, ListOptions{Completed: ptr(false)}); fmt.Sprint(got) != fmt.Sprint(tt.wantOpen) {
+				t.Errorf("open todos = %q, want %q", got, tt.wantOpen)
+			}
+			if got := titles(t, svc, alice, ListOptions{Trashed: true}); fmt.Sprint(got) != fmt.Sprint(tt.wantTrashed) {
+				t.Errorf("trashed todos = %q, want %q", got, tt.wantTrashed)
+			}
+			page, err := svc.Events(alice, EventFilter{TodoID: &private.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			aliceID, err := currentUser(alice)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range page.Events {
+				if e.ActorID != aliceID {
+					t.Errorf("bob changed alice's private todo: %+v", e)
+				}
+			}
+		})
+	}
+}
+
+// recurringDue is the due date of the recurring todos of the tests, far
+// enough ahead that the next occurrence follows it rather than the time
+// the todo is completed.
+var recurringDue = time.Date(2100, 5, 3, 17, 0, 0, 0, time.UTC)
+
+func TestServiceRecurrence(t *testing.T) {
+	due := recurringDue
+
+	tests := []struct {
+		name       string
+		recurrence string
+		wantDue    time.Time
+	}{
+		{"daily", "FREQ=DAILY", due.AddDate(0, 0, 1)},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", due.AddDate(0, 0, 2)},
+		{"weekly", "FREQ=WEEKLY", due.AddDate(0, 0, 7)},
+		{"monthly", "FREQ=MONTHLY", due.AddDate(0, 1, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			created, err := svc.Create(ctx, CreateTodoRequest{Title: "Water plants", DueAt: &due, Recurrence: tt.recurrence})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			done, err := svc.Toggle(ctx, created.ID, 0, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if done.Recurrence != "" {
+				t.Errorf("completed occurrence still recurs: %q", done.Recurrence)
+			}
+			next := openTodos(t, svc, ctx)
+			if len(next) != 1 {
+				t.Fatalf("got %d open todos, want the next occurrence", len(next))
+			}
+			if next[0].DueAt == nil || !next[0].DueAt.Equal(tt.wantDue) {
+				t.Errorf("next DueAt = %v, want %v", next[0].DueAt, tt.wantDue)
+			}
+			if next[0].Recurrence != tt.recurrence {
+				t.Errorf("next Recurrence = %q, want %q", next[0].Recurrence, tt.recurrence)
+			}
+		})
+	}
+}
+
+func TestServiceRecurrenceTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks in Berlin go forward on Sunday 28 March 2100.
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2100, month, day, hour, min, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		name       string
+		recurrence string
+		due        time.Time
+		wantDue    time.Time
+	}{
+		{"daily across DST", "FREQ=DAILY;X-TZID=Europe/Berlin", at(3, 27, 9, 0), at(3, 28, 9, 0)},
+		{"daily across DST in UTC", "FREQ=DAILY", at(3, 27, 9, 0), at(3, 28, 10, 0)},
+		{"weekly across DST", "FREQ=WEEKLY;X-TZID=Europe/Berlin", at(3, 22, 9, 0), at(3, 29, 9, 0)},
+		// Monday 00:30 in Berlin is still Sunday in UTC.
+		{"weekday near midnight", "FREQ=WEEKLY;BYDAY=MO,TH;X-TZID=Europe/Berlin", at(5, 3, 0, 30), at(5, 6, 0, 30)},
+		{"day of month near midnight", "FREQ=MONTHLY;X-TZID=Europe/Berlin", at(5, 1, 0, 30), at(6, 1, 0, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			created, err := svc.Create(ctx, CreateTodoRequest{Title: "Water plants", DueAt: &tt.due, Recurrence: tt.recurrence})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := svc.Toggle(ctx, created.ID, 0, false); err != nil {
+				t.Fatal(err)
+			}
+			next := openTodos(t, svc, ctx)
+			if len(next) != 1 {
+				t.Fatalf("got %d open todos, want the next occurrence", len(next))
+			}
+			if next[0].DueAt == nil || !next[0].DueAt.Equal(tt.wantDue) {
+				t.Errorf("next DueAt = %v, want %v", next[0].DueAt, tt.wantDue.UTC())
+			}
+		})
+	}
+}
+
+func TestServiceRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		recurrence string
+		want       string
+		wantErr    error
+	}{
+		{"rrule:freq=weekly;byday=th,mo", "FREQ=WEEKLY;BYDAY=MO,TH", nil},
+		{"freq=daily;x-tzid=America/New_York", "FREQ=DAILY;X-TZID=America/New_York", nil},
+		{"FREQ=DAILY;X-TZID=Mars/Olympus_Mons", "", ErrValidation},
+		{"FREQ=DAILY;X-TZID=Local", "", ErrValidation},
+		{"FREQ=YEARLY", "", ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recurrence, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+			due := recurringDue
+			got, err := svc.Create(ctx, CreateTodoRequest{Title: "Water plants", DueAt: &due, Recurrence: tt.recurrence})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && got.Recurrence != tt.want {
+				t.Errorf("Recurrence = %q, want %q", got.Recurrence, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceCascadeRecurrence(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := newUser(t, repo, "alice@example.com")
+	due := recurringDue
+
+	parent, err := svc.Create(ctx, CreateTodoRequest{Title: "Clean house"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = svc.Create(ctx, CreateTodoRequest{Title: "Water plants", ParentID: &parent.ID, DueAt: &due, Recurrence: "FREQ=DAILY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.Toggle(ctx, parent.ID, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	next := openTodos(t, svc, ctx)
+	if len(next) != 1 {
+		t.Fatalf("got %d open todos, want the next occurrence of the subtask", len(next))
+	}
+	if next[0].ParentID == nil || *next[0].ParentID != parent.ID {
+		t.Errorf("next occurrence has parent %v, want %d", next[0].ParentID, parent.ID)
+	}
+	if want := due.AddDate(0, 0, 1); next[0].DueAt == nil || !next[0].DueAt.Equal(want) {
+		t.Errorf("next DueAt = %v, want %v", next[0].DueAt, want)
+	}
+}
+
+func openTodos(t *testing.T, svc Service, ctx context.Context) []Todo {
+	t.Helper()
+	open := false
+	page, err := svc.List(ctx, ListOptions{Completed: &open})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page.Todos
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
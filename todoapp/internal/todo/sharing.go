@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"slices"
+	"time"
+)
+
+// Role is a member's permission level in a shared list. Each role
+// includes the permissions of the ones before it:
+//
+//   - viewer: see the list and its todos
+//   - editor: also create, change and delete the list's todos
+//   - owner: also rename or delete the list and manage its members
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+var roles = []Role{RoleViewer, RoleEditor, RoleOwner}
+
+// allows reports whether r includes the permissions of want.
+func (r Role) allows(want Role) bool {
+	i := slices.Index(roles, r)
+	return i >= 0 && i >= slices.Index(roles, want)
+}
+
+func validateRole(r Role) error {
+	if !slices.Contains(roles, r) {
+		return validationError("role must be viewer, editor or owner")
+	}
+	return nil
+}
+
+// Member is a user's membership of a shared list.
+type Member struct {
+	ListID   int       `json:"list_id" example:"1"`
+	UserID   int       `json:"user_id" example:"2"`
+	Email    string    `json:"email" example:"alice@example.com"`
+	Role     Role      `json:"role" example:"editor"`
+	JoinedAt time.Time `json:"joined_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// Invitation is a pending offer of membership of a list to whoever
+// registers, or has registered, with Email.
+type Invitation struct {
+	ID        int       `json:"id" example:"1"`
+	ListID    int       `json:"list_id" example:"1"`
+	ListName  string    `json:"list_name" example:"Home"`
+	Email     string    `json:"email" example:"alice@example.com"`
+	Role      Role      `json:"role" example:"editor"`
+	InvitedBy int       `json:"invited_by" example:"1"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+}
+
+// InvitationRequest represents the request body for inviting a user to
+// a list.
+type InvitationRequest struct {
+	Email string `json:"email" example:"alice@example.com"`
+	Role  Role   `json:"role" example:"editor"`
+}
+
+// MemberRequest represents the request body for changing a member's
+// role.
+type MemberRequest struct {
+	Role Role `json:"role" example:"viewer"`
+}
@@ -0,0 +1,45 @@
+package todo
+
+// maxDepth bounds how many ancestors a todo may have. It also stops
+// ancestor walks should the hierarchy ever contain a cycle.
+const maxDepth = 100
+
+// Progress counts a todo's direct subtasks.
+type Progress struct {
+	Completed int `json:"completed" example:"2"`
+	Total     int `json:"total" example:"5"`
+}
+
+// TodoNode is a todo with its subtasks, as returned by the tree view.
+type TodoNode struct {
+	Todo
+	Children []TodoNode `json:"children"`
+}
+
+// buildTree nests todos under their parents, keeping the order of todos
+// among siblings. Todos whose parent is not in todos become roots.
+func buildTree(todos []Todo) []TodoNode {
+	present := make(map[int]bool, len(todos))
+	for _, t := range todos {
+		present[t.ID] = true
+	}
+	children := make(map[int][]Todo)
+	var roots []Todo
+	for _, t := range todos {
+		if t.ParentID != nil && present[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var build func(ts []Todo) []TodoNode
+	build = func(ts []Todo) []TodoNode {
+		nodes := make([]TodoNode, 0, len(ts))
+		for _, t := range ts {
+			nodes = append(nodes, TodoNode{Todo: t, Children: build(children[t.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
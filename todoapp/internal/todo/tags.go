@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTagNameLength is the longest tag name accepted, in characters.
+const maxTagNameLength = 50
+
+// Tag is a label that can be attached to any number of todos. Each user
+// has their own tags, whose names are unique ignoring case.
+type Tag struct {
+	ID      int    `json:"id" example:"1"`
+	Name    string `json:"name" example:"errands"`
+	OwnerID int    `json:"-"`
+}
+
+// TagRequest represents the request body for creating or renaming a tag.
+type TagRequest struct {
+	Name string `json:"name" example:"errands"`
+}
+
+// AttachTagRequest represents the request body for tagging a todo.
+type AttachTagRequest struct {
+	TagID int `json:"tag_id" example:"1"`
+}
+
+// normalizeTagName trims name and checks it is a valid tag name. Commas
+// are rejected because GET /todos takes tag filters as a comma-separated
+// list.
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", validationError("tag name is required")
+	case utf8.RuneCountInString(name) > maxTagNameLength:
+		return "", validationError("tag name must be at most %d characters", maxTagNameLength)
+	case strings.Contains(name, ","):
+		return "", validationError("tag name must not contain commas")
+	}
+	return name, nil
+}
+
+// hasTags reports whether t carries all (or, unless all is set, any) of
+// the named tags, ignoring case.
+func (t Todo) hasTags(names []string, all bool) bool {
+	for _, name := range names {
+		found := false
+		for _, tag := range t.Tags {
+			if strings.EqualFold(tag.Name, name) {
+				found = true
+				break
+			}
+		}
+		if found && !all {
+			return true
+		}
+		if !found && all {
+			return false
+		}
+	}
+	return all
+}
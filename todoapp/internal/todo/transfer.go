@@ -0,0 +1,838 @@
+package todo
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// FileFormat is a file format todos are exported to and imported from.
+type FileFormat string
+
+const (
+	// FormatJSON is a JSON array of todos as the API returns them.
+	FormatJSON FileFormat = "json"
+	// FormatCSV has a header row naming some of csvColumns, in any
+	// order.
+	FormatCSV FileFormat = "csv"
+	// FormatTodoTxt is the todo.txt format, one todo per line, with the
+	// fields it has no syntax for as key:value pairs.
+	FormatTodoTxt FileFormat = "todotxt"
+)
+
+func (f FileFormat) validate() error {
+	switch f {
+	case FormatJSON, FormatCSV, FormatTodoTxt:
+		return nil
+	}
+	return validationError("format must be json, csv or todotxt")
+}
+
+// contentType returns the media type of files in the format.
+func (f FileFormat) contentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatTodoTxt:
+		return "text/plain; charset=utf-8"
+	}
+	return "application/json"
+}
+
+// filename returns the usual name of a file in the format.
+func (f FileFormat) filename() string {
+	switch f {
+	case FormatCSV:
+		return "todos.csv"
+	case FormatTodoTxt:
+		return "todo.txt"
+	}
+	return "todos.json"
+}
+
+// importBatchSize is the most rows an import saves in one transaction.
+const importBatchSize = 500
+
+// ImportRequest describes an import; the file itself is read separately.
+type ImportRequest struct {
+	Format FileFormat
+	// DryRun reports what the import would do without saving anything.
+	DryRun bool
+}
+
+// ImportStatus is what an import did with one row of the file.
+type ImportStatus string
+
+const (
+	ImportCreated  ImportStatus = "created"
+	ImportSkipped  ImportStatus = "skipped"
+	ImportRejected ImportStatus = "rejected"
+)
+
+// ImportResult is the outcome of one row of an import.
+type ImportResult struct {
+	// Row is the line the row starts on for CSV and todo.txt, and its
+	// position in the array, from 1, for JSON.
+	Row    int
+	Status ImportStatus
+	// ID is the todo the row was imported as or, if it was skipped, the
+	// todo it duplicates. A dry run reports the ids the todos would have
+	// had, which may be given to other todos later.
+	ID    int
+	Title string
+	// Err says why the row was rejected.
+	Err error
+}
+
+// ImportReport is the response body of an import.
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run" example:"false"`
+	Created  int               `json:"created" example:"2"`
+	Skipped  int               `json:"skipped" example:"1"`
+	Rejected int               `json:"rejected" example:"0"`
+	Rows     []ImportRowReport `json:"rows"`
+	// Error says why the import stopped before the end of the file. The
+	// rows reported were saved all the same.
+	Error string `json:"error,omitempty"`
+}
+
+// ImportRowReport is the outcome of one row of an import, in file
+// order.
+type ImportRowReport struct {
+	Row    int          `json:"row" example:"2"`
+	Status ImportStatus `json:"status" example:"created"`
+	ID     int          `json:"id,omitempty" example:"7"`
+	Title  string       `json:"title" example:"Buy groceries"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// errDryRun rolls back the transactions of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// importRow is one todo read from an import file. Its Todo holds the
+// fields imports keep, with the row's own id, which the parent_id of
+// later rows refers to, and only the names of its tags.
+type importRow struct {
+	Row  int
+	Todo Todo
+	// Err is set if the row could not be parsed.
+	Err error
+}
+
+// todoReader reads the rows of an import file one at a time.
+type todoReader interface {
+	// Next returns the next row, or io.EOF after the last one. Any other
+	// error means the rest of the file cannot be read.
+	Next() (importRow, error)
+}
+
+// readBatch reads the next rows of an import, at most importBatchSize
+// of them. last is set once the end of the file is reached.
+func readBatch(dec todoReader) (rows []importRow, last bool, err error) {
+	for len(rows) < importBatchSize {
+		row, err := dec.Next()
+		if err == io.EOF {
+			return rows, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, false, nil
+}
+
+func newTodoReader(f FileFormat, r io.Reader) todoReader {
+	switch f {
+	case FormatCSV:
+		return &csvTodoReader{r: csv.NewReader(r)}
+	case FormatTodoTxt:
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 0, 64*1024), maxTodoTxtLine)
+		return &todoTxtReader{s: s}
+	}
+	return &jsonTodoReader{dec: json.NewDecoder(r)}
+}
+
+// todoWriter writes the todos of an export one at a time.
+type todoWriter interface {
+	Write(t Todo) error
+	// Close finishes the file, which is valid even if no todo was
+	// written. Nothing is written before the first Write or Close.
+	Close() error
+}
+
+func newTodoWriter(f FileFormat, w io.Writer) todoWriter {
+	switch f {
+	case FormatCSV:
+		return &csvTodoWriter{w: csv.NewWriter(w)}
+	case FormatTodoTxt:
+		return &todoTxtWriter{w: bufio.NewWriter(w)}
+	}
+	return &jsonTodoWriter{w: w}
+}
+
+// completedState decides whether an imported todo is complete when the
+// file may leave it unsaid: then a completion time means it is.
+func completedState(completed *bool, completedAt *time.Time) bool {
+	if completed != nil {
+		return *completed
+	}
+	return completedAt != nil
+}
+
+type jsonTodoWriter struct {
+	w io.Writer
+	n int
+}
+
+func (e *jsonTodoWriter) Write(t Todo) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if e.n == 0 {
+		sep = "[\n"
+	}
+	e.n++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonTodoWriter) Close() error {
+	end := "\n]\n"
+	if e.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+type jsonTodoReader struct {
+	dec     *json.Decoder
+	started bool
+	n       int
+}
+
+func (d *jsonTodoReader) Next() (importRow, error) {
+	if !d.started {
+		if tok, err := d.dec.Token(); err != nil || tok != json.Delim('[') {
+			return importRow{}, validationError("a JSON import must be an array of todos")
+		}
+		d.started = true
+	}
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err != nil {
+			return importRow{}, validationError("invalid JSON after todo %d: %v", d.n, err)
+		}
+		return importRow{}, io.EOF
+	}
+
+	// Decoding into a RawMessage first separates a syntax error, which
+	// ends the import, from a todo with fields of the wrong type.
+	d.n++
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return importRow{}, validationError("invalid JSON in todo %d: %v", d.n, err)
+	}
+	row := importRow{Row: d.n}
+	var v struct {
+		Todo
+		Completed *bool `json:"completed"`
+	}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		row.Err = validationError("invalid todo: %v", err)
+		return row, nil
+	}
+	row.Todo = v.Todo
+	row.Todo.Completed = completedState(v.Completed, v.CompletedAt)
+	return row, nil
+}
+
+// csvColumns are the columns of a CSV export. tags holds tag names
+// separated by commas, which tag names cannot contain.
+var csvColumns = []string{"id", "title", "completed", "created_at", "completed_at", "due_at", "remind_at", "priority", "list_id", "parent_id", "recurrence", "tags"}
+
+type csvTodoWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (e *csvTodoWriter) Write(t Todo) error {
+	if !e.started {
+		e.started = true
+		if err := e.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+	tags := make([]string, len(t.Tags))
+	for i, tag := range t.Tags {
+		tags[i] = tag.Name
+	}
+	return e.w.Write([]string{
+		strconv.Itoa(t.ID),
+		t.Title,
+		strconv.FormatBool(t.Completed),
+		formatTime(&t.CreatedAt),
+		formatTime(t.CompletedAt),
+		formatTime(t.DueAt),
+		formatTime(t.RemindAt),
+		strconv.Itoa(t.Priority),
+		formatInt(t.ListID),
+		formatInt(t.ParentID),
+		t.Recurrence,
+		strings.Join(tags, ","),
+	})
+}
+
+func (e *csvTodoWriter) Close() error {
+	if !e.started {
+		if err := e.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type csvTodoReader struct {
+	r *csv.Reader
+	// cols maps the names in the header row to their positions.
+	cols map[string]int
+}
+
+func (d *csvTodoReader) Next() (importRow, error) {
+	if d.cols == nil {
+		header, err := d.r.Read()
+		if err == io.EOF {
+			return importRow{}, io.EOF
+		}
+		if err != nil {
+			return importRow{}, validationError("invalid CSV header: %v", err)
+		}
+		d.cols = make(map[string]int, len(header))
+		for i, name := range header {
+			name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+			if !slices.Contains(csvColumns, name) {
+				return importRow{}, validationError("unknown CSV column %q; columns are %s", name, strings.Join(csvColumns, ", "))
+			}
+			d.cols[name] = i
+		}
+		if _, ok := d.cols["title"]; !ok {
+			return importRow{}, validationError("the CSV header must have a title column")
+		}
+	}
+
+	record, err := d.r.Read()
+	if err == io.EOF {
+		return importRow{}, io.EOF
+	}
+	var pe *csv.ParseError
+	if errors.As(err, &pe) && errors.Is(pe.Err, csv.ErrFieldCount) {
+		return importRow{Row: pe.StartLine, Err: validationError("the row has %d fields but the header has %d", len(record), len(d.cols))}, nil
+	}
+	if err != nil {
+		return importRow{}, validationError("invalid CSV: %v", err)
+	}
+	line, _ := d.r.FieldPos(0)
+	row := importRow{Row: line}
+	row.Todo, row.Err = d.parse(record)
+	return row, nil
+}
+
+// parse reads the fields of a row. On error the todo still has its
+// title, for reporting.
+func (d *csvTodoReader) parse(record []string) (Todo, error) {
+	get := func(name string) string {
+		if i, ok := d.cols[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	t := Todo{Title: get("title"), Recurrence: get("recurrence")}
+	var err error
+	ints := []struct {
+		name string
+		dst  **int
+	}{
+		{"list_id", &t.ListID},
+		{"parent_id", &t.ParentID},
+	}
+	for _, f := range ints {
+		if *f.dst, err = parseOptionalInt(f.name, get(f.name)); err != nil {
+			return Todo{Title: t.Title}, err
+		}
+	}
+	if id, err := parseOptionalInt("id", get("id")); err != nil {
+		return Todo{Title: t.Title}, err
+	} else if id != nil {
+		t.ID = *id
+	}
+	if v := get("priority"); v != "" {
+		if t.Priority, err = strconv.Atoi(v); err != nil {
+			return Todo{Title: t.Title}, validationError("priority must be an integer")
+		}
+	}
+
+	times := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"completed_at", &t.CompletedAt},
+		{"due_at", &t.DueAt},
+		{"remind_at", &t.RemindAt},
+	}
+	for _, f := range times {
+		if *f.dst, err = parseOptionalTime(f.name, get(f.name)); err != nil {
+			return Todo{Title: t.Title}, err
+		}
+	}
+	createdAt, err := parseOptionalTime("created_at", get("created_at"))
+	if err != nil {
+		return Todo{Title: t.Title}, err
+	}
+	if createdAt != nil {
+		t.CreatedAt = *createdAt
+	}
+
+	var completed *bool
+	if v := get("completed"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return Todo{Title: t.Title}, validationError("completed must be true or false")
+		}
+		completed = &b
+	}
+	t.Completed = completedState(completed, t.CompletedAt)
+
+	for _, name := range strings.Split(get("tags"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			t.Tags = append(t.Tags, Tag{Name: name})
+		}
+	}
+	return t, nil
+}
+
+// maxTodoTxtLine is the longest todo.txt line an import accepts.
+const maxTodoTxtLine = 1 << 20
+
+// todoTxtPriorities are the todo.txt priorities of PriorityHigh,
+// PriorityMedium and PriorityLow. Imports read the letters after C as
+// PriorityLow.
+var todoTxtPriorities = map[int]byte{PriorityHigh: 'A', PriorityMedium: 'B', PriorityLow: 'C'}
+
+type todoTxtWriter struct {
+	w *bufio.Writer
+}
+
+// Write writes t in the todo.txt layout of completion mark, dates,
+// priority and title, then its tags as @contexts and the rest as
+// key:value pairs: due, remind, rec, list, parent and id, pri for a
+// completed todo, and created and completed holding the exact times the
+// dates round down. Tags whose names contain spaces are left out, and
+// line breaks in the title become spaces.
+func (e *todoTxtWriter) Write(t Todo) error {
+	var parts []string
+	const date = "2006-01-02"
+	if t.Completed {
+		parts = append(parts, "x")
+		if t.CompletedAt != nil {
+			parts = append(parts, t.CompletedAt.UTC().Format(date))
+		}
+	}
+	parts = append(parts, t.CreatedAt.UTC().Format(date))
+	pri, hasPri := todoTxtPriorities[t.Priority]
+	if hasPri && !t.Completed {
+		parts = append(parts, "("+string(pri)+")")
+	}
+	parts = append(parts, strings.Join(strings.Fields(t.Title), " "))
+	for _, tag := range t.Tags {
+		if !strings.ContainsFunc(tag.Name, unicode.IsSpace) {
+			parts = append(parts, "@"+tag.Name)
+		}
+	}
+
+	keys := []struct{ key, value string }{
+		{"due", formatTime(t.DueAt)},
+		{"remind", formatTime(t.RemindAt)},
+		{"rec", t.Recurrence},
+		{"list", formatInt(t.ListID)},
+		{"parent", formatInt(t.ParentID)},
+		{"id", strconv.Itoa(t.ID)},
+		{"created", formatTime(&t.CreatedAt)},
+		{"completed", formatTime(t.CompletedAt)},
+	}
+	if hasPri && t.Completed {
+		keys = append(keys, struct{ key, value string }{"pri", string(pri)})
+	}
+	for _, kv := range keys {
+		if kv.value != "" {
+			parts = append(parts, kv.key+":"+kv.value)
+		}
+	}
+
+	_, err := e.w.WriteString(strings.Join(parts, " ") + "\n")
+	return err
+}
+
+func (e *todoTxtWriter) Close() error {
+	return e.w.Flush()
+}
+
+type todoTxtReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func (d *todoTxtReader) Next() (importRow, error) {
+	for d.s.Scan() {
+		d.line++
+		line := strings.TrimSpace(d.s.Text())
+		if line == "" {
+			continue
+		}
+		row := importRow{Row: d.line}
+		row.Todo, row.Err = parseTodoTxt(line)
+		return row, nil
+	}
+	if err := d.s.Err(); err != nil {
+		return importRow{}, validationError("cannot read line %d: %v", d.line+1, err)
+	}
+	return importRow{}, io.EOF
+}
+
+// parseTodoTxt parses one todo.txt line as written by todoTxtWriter, or
+// by any other todo.txt tool. Dates without a time are midnight UTC, and
+// key:value pairs with other keys are left in the title. On error the
+// todo still has its title, for reporting.
+func parseTodoTxt(line string) (Todo, error) {
+	var t Todo
+	fields := strings.Fields(line)
+	if fields[0] == "x" {
+		t.Completed = true
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		if pri, ok := parseTodoTxtPriority(fields[0]); ok {
+			t.Priority = pri
+			fields = fields[1:]
+		}
+	}
+	var dates []time.Time
+	for len(fields) > 0 && len(dates) < 2 {
+		d, err := time.Parse("2006-01-02", fields[0])
+		if err != nil {
+			break
+		}
+		dates = append(dates, d)
+		fields = fields[1:]
+	}
+	// A completed todo's first date is its completion date.
+	if t.Completed && len(dates) > 0 {
+		t.CompletedAt = &dates[0]
+		dates = dates[1:]
+	}
+	if len(dates) > 0 {
+		t.CreatedAt = dates[0]
+	}
+
+	var words []string
+	var firstErr error
+	for _, field := range fields {
+		if name, ok := strings.CutPrefix(field, "@"); ok && name != "" {
+			t.Tags = append(t.Tags, Tag{Name: name})
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" {
+			words = append(words, field)
+			continue
+		}
+		var err error
+		switch key {
+		case "due":
+			t.DueAt, err = parseTodoTxtTime(key, value)
+		case "remind":
+			t.RemindAt, err = parseTodoTxtTime(key, value)
+		case "created":
+			var at *time.Time
+			if at, err = parseTodoTxtTime(key, value); at != nil {
+				t.CreatedAt = *at
+			}
+		case "completed":
+			t.CompletedAt, err = parseTodoTxtTime(key, value)
+		case "rec":
+			t.Recurrence = value
+		case "list":
+			t.ListID, err = parseOptionalInt(key, value)
+		case "parent":
+			t.ParentID, err = parseOptionalInt(key, value)
+		case "id":
+			var id *int
+			if id, err = parseOptionalInt(key, value); id != nil {
+				t.ID = *id
+			}
+		case "pri":
+			pri, ok := parseTodoTxtPriority("(" + value + ")")
+			if !ok {
+				err = validationError("pri must be a letter from A to Z")
+			}
+			t.Priority = pri
+		default:
+			words = append(words, field)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	t.Title = strings.Join(words, " ")
+	return t, firstErr
+}
+
+// parseTodoTxtPriority parses a todo.txt priority such as "(A)".
+func parseTodoTxtPriority(s string) (int, bool) {
+	if len(s) != 3 || s[0] != '(' || s[2] != ')' || s[1] < 'A' || s[1] > 'Z' {
+		return 0, false
+	}
+	for pri, letter := range todoTxtPriorities {
+		if letter == s[1] {
+			return pri, true
+		}
+	}
+	return PriorityLow, true
+}
+
+// parseTodoTxtTime parses an RFC 3339 time or a date.
+func parseTodoTxtTime(key, value string) (*time.Time, error) {
+	if d, err := time.Parse("2006-01-02", value); err == nil {
+		return &d, nil
+	}
+	return parseOptionalTime(key, value)
+}
+
+func parseOptionalTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, validationError("%s must be an RFC 3339 time", name)
+	}
+	return &t, nil
+}
+
+func parseOptionalInt(name, value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, validationError("%s must be an integer", name)
+	}
+	return &n, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// importKey identifies a todo for finding duplicates in an import: a
+// todo with the same title created at the same time, to the microsecond
+// Postgres keeps, is taken to be the same todo.
+type importKey struct {
+	title     string
+	createdAt int64
+}
+
+func keyOf(t Todo) importKey {
+	return importKey{title: t.Title, createdAt: t.CreatedAt.Round(time.Microsecond).UnixMicro()}
+}
+
+// importer creates the rows of one import. It lives across the
+// transactions of the import's batches.
+type importer struct {
+	s *service
+	// seen maps the todos the user can see, including those imported so
+	// far, to their ids.
+	seen map[importKey]int
+	// ids maps the ids rows have in the file to the todos they were
+	// imported as or skipped for.
+	ids map[int]int
+	// tags holds the user's tags by lower-case name.
+	tags map[string]Tag
+	// newTodos and newTags are the todos and the lower-case names of the
+	// tags created by the current batch.
+	newTodos []int
+	newTags  []string
+	// dropped holds the todos created by batches that were rolled back.
+	dropped map[int]bool
+}
+
+// newImporter starts an import for the user in ctx.
+func newImporter(ctx context.Context, s *service) (*importer, error) {
+	imp := &importer{s: s, seen: make(map[importKey]int), ids: make(map[int]int), tags: make(map[string]Tag), dropped: make(map[int]bool)}
+	opts := ListOptions{Limit: MaxPageSize}
+	for {
+		page, err := s.repo.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page.Todos {
+			imp.seen[keyOf(t)] = t.ID
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		opts.PageToken = page.NextPageToken
+	}
+	tags, err := s.repo.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		imp.tags[strings.ToLower(tag.Name)] = tag
+	}
+	return imp, nil
+}
+
+// add imports one row. Rows that fail the checks of Create are rejected
+// in the result; the error is only set if the import cannot go on.
+func (imp *importer) add(ctx context.Context, row importRow) (ImportResult, error) {
+	res := ImportResult{Row: row.Row, Status: ImportRejected, Title: row.Todo.Title, Err: row.Err}
+	if row.Err != nil {
+		return res, nil
+	}
+	src := row.Todo
+
+	if !src.CreatedAt.IsZero() {
+		if id, ok := imp.seen[keyOf(src)]; ok {
+			if src.ID != 0 {
+				imp.ids[src.ID] = id
+			}
+			res.Status, res.ID = ImportSkipped, id
+			return res, nil
+		}
+	}
+
+	t := Todo{
+		Title:      src.Title,
+		Completed:  src.Completed,
+		CreatedAt:  src.CreatedAt,
+		DueAt:      src.DueAt,
+		RemindAt:   src.RemindAt,
+		Priority:   src.Priority,
+		ListID:     src.ListID,
+		Recurrence: src.Recurrence,
+	}
+	if src.ParentID != nil {
+		id, ok := imp.ids[*src.ParentID]
+		if !ok {
+			res.Err = validationError("parent_id %d is not the id of an earlier row", *src.ParentID)
+			return res, nil
+		}
+		// A parent created by a rolled back batch of a dry run is gone,
+		// so the row is checked as a top-level todo.
+		if !imp.dropped[id] {
+			t.ParentID = &id
+		}
+	}
+	if t.Completed {
+		t.CompletedAt = src.CompletedAt
+		if t.CompletedAt == nil {
+			now := time.Now()
+			t.CompletedAt = &now
+		}
+	}
+
+	var names []string
+	for _, tag := range src.Tags {
+		name, err := normalizeTagName(tag.Name)
+		if err != nil {
+			res.Err = err
+			return res, nil
+		}
+		if !slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) }) {
+			names = append(names, name)
+		}
+	}
+
+	created, err := imp.s.create(ctx, t)
+	if err != nil {
+		if rejectsRow(err) {
+			res.Err = err
+			return res, nil
+		}
+		return res, err
+	}
+	for _, name := range names {
+		tag, ok := imp.tags[strings.ToLower(name)]
+		if !ok {
+			if tag, err = imp.s.repo.CreateTag(ctx, name); err != nil {
+				return res, err
+			}
+			imp.tags[strings.ToLower(name)] = tag
+			imp.newTags = append(imp.newTags, strings.ToLower(name))
+		}
+		if _, err := imp.s.repo.AttachTag(ctx, created.ID, tag.ID); err != nil {
+			return res, err
+		}
+	}
+
+	imp.newTodos = append(imp.newTodos, created.ID)
+	imp.seen[keyOf(src)] = created.ID
+	imp.seen[keyOf(created)] = created.ID
+	if src.ID != 0 {
+		imp.ids[src.ID] = created.ID
+	}
+	res.Status, res.ID, res.Err = ImportCreated, created.ID, nil
+	return res, nil
+}
+
+// endBatch ends the current batch. If its transaction was rolled back,
+// the tags it created are forgotten, and the todos it created are
+// dropped.
+func (imp *importer) endBatch(committed bool) {
+	if !committed {
+		for _, name := range imp.newTags {
+			delete(imp.tags, name)
+		}
+		for _, id := range imp.newTodos {
+			imp.dropped[id] = true
+		}
+	}
+	imp.newTodos, imp.newTags = nil, nil
+}
+
+// rejectsRow reports whether err is a problem with an imported row
+// rather than one that stops the import.
+func rejectsRow(err error) bool {
+	for _, target := range []error{ErrValidation, ErrNotFound, ErrForbidden, ErrConflict} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
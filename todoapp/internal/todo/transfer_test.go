@@ -0,0 +1,256 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	due := time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+
+	for _, format := range []FileFormat{FormatJSON, FormatCSV, FormatTodoTxt} {
+		t.Run(string(format), func(t *testing.T) {
+			svc, repo := newTestService(t)
+			alice := newUser(t, repo, "alice@example.com")
+			bob := newUser(t, repo, "bob@example.com")
+
+			parent, err := svc.Create(alice, CreateTodoRequest{Title: "Clean house", DueAt: &due, Priority: PriorityHigh})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.Create(alice, CreateTodoRequest{Title: "Water plants", ParentID: &parent.ID}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.Toggle(alice, parent.ID, 0, false); err != nil {
+				t.Fatal(err)
+			}
+
+			var file bytes.Buffer
+			if err := svc.Export(alice, format, ListOptions{Sort: SortByPosition}, &file); err != nil {
+				t.Fatal(err)
+			}
+			data := file.Bytes()
+
+			results, err := svc.Import(bob, ImportRequest{Format: format}, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statuses(results); got != "created created" {
+				t.Fatalf("statuses = %s, want both created", got)
+			}
+			imported, err := svc.List(bob, ListOptions{Sort: SortByPosition})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(imported.Todos) != 2 {
+				t.Fatalf("imported %d todos, want 2", len(imported.Todos))
+			}
+			got, child := imported.Todos[0], imported.Todos[1]
+			if got.Title != "Clean house" || !got.Completed || got.Priority != PriorityHigh ||
+				got.DueAt == nil || !got.DueAt.Equal(due) {
+				t.Errorf("imported %+v, want the exported todo", got)
+			}
+			if child.ParentID == nil || *child.ParentID != got.ID {
+				t.Errorf("imported subtask has parent %v, want %d", child.ParentID, got.ID)
+			}
+
+			// Importing the same file again finds the todos already there.
+			results, err = svc.Import(bob, ImportRequest{Format: format}, bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statuses(results); got != "skipped skipped" {
+				t.Errorf("statuses of a second import = %s, want both skipped", got)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name   string
+		format FileFormat
+		file   string
+		dryRun bool
+		// want are the statuses of the rows, and wantTitles the titles of
+		// the todos saved.
+		want       string
+		wantTitles []string
+	}{
+		{
+			name:       "json",
+			format:     FormatJSON,
+			file:       `[{"title":"a"},{"title":"b","priority":2}]`,
+			want:       "created created",
+			wantTitles: []string{"a", "b"},
+		},
+		{
+			name:       "rejected rows",
+			format:     FormatJSON,
+			file:       `[{"title":""},{"title":"b","priority":9},{"title":"c"}]`,
+			want:       "rejected rejected created",
+			wantTitles: []string{"c"},
+		},
+		{
+			name:       "unknown parent",
+			format:     FormatJSON,
+			file:       `[{"id":1,"title":"a"},{"id":2,"title":"b","parent_id":3}]`,
+			want:       "created rejected",
+			wantTitles: []string{"a"},
+		},
+		{
+			name:       "duplicate rows",
+			format:     FormatJSON,
+			file:       `[{"title":"a","created_at":"2024-05-01T10:00:00Z"},{"title":"a","created_at":"2024-05-01T10:00:00Z"}]`,
+			want:       "created skipped",
+			wantTitles: []string{"a"},
+		},
+		{
+			name:       "csv",
+			format:     FormatCSV,
+			file:       "title,priority,tags\na,1,home\nb,,\n",
+			want:       "created created",
+			wantTitles: []string{"a", "b"},
+		},
+		{
+			name:       "todo.txt",
+			format:     FormatTodoTxt,
+			file:       "(A) a @home\nx b\n",
+			want:       "created created",
+			wantTitles: []string{"a", "b"},
+		},
+		{
+			name:       "dry run",
+			format:     FormatJSON,
+			file:       `[{"title":"a"}]`,
+			dryRun:     true,
+			want:       "created",
+			wantTitles: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+
+			results, err := svc.Import(ctx, ImportRequest{Format: tt.format, DryRun: tt.dryRun}, strings.NewReader(tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := statuses(results); got != tt.want {
+				t.Errorf("statuses = %s, want %s", got, tt.want)
+			}
+			got := titles(t, svc, ctx, ListOptions{Sort: SortByPosition})
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantTitles) {
+				t.Errorf("titles = %q, want %q", got, tt.wantTitles)
+			}
+		})
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		format FileFormat
+		file   string
+	}{
+		{"unknown format", "xml", `<todos/>`},
+		{"malformed json", FormatJSON, `[{"title":`},
+		{"unknown csv column", FormatCSV, "title,colour\na,red\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+
+			_, err := svc.Import(ctx, ImportRequest{Format: tt.format}, strings.NewReader(tt.file))
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("Import error = %v, want %v", err, ErrValidation)
+			}
+			if got := titles(t, svc, ctx, ListOptions{}); len(got) != 0 {
+				t.Errorf("failed import saved %q", got)
+			}
+		})
+	}
+}
+
+func TestImportBatches(t *testing.T) {
+	// The first row of the second batch is a subtask of the first row of
+	// the first, and both have the same tag.
+	var file strings.Builder
+	file.WriteString("id,title,parent_id,tags\n1,parent,,home\n")
+	for i := 1; i < importBatchSize; i++ {
+		fmt.Fprintf(&file, ",%d,,\n", i)
+	}
+	file.WriteString(",child,1,home\n")
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprint("dry run ", dryRun), func(t *testing.T) {
+			svc, repo := newTestService(t)
+			ctx := newUser(t, repo, "alice@example.com")
+
+			results, err := svc.Import(ctx, ImportRequest{Format: FormatCSV, DryRun: dryRun}, strings.NewReader(file.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != importBatchSize+1 {
+				t.Fatalf("got %d results, want %d", len(results), importBatchSize+1)
+			}
+			for _, res := range results {
+				if res.Status != ImportCreated {
+					t.Fatalf("row %d %s: %v, want created", res.Row, res.Status, res.Err)
+				}
+			}
+			if got := titles(t, svc, ctx, ListOptions{}); dryRun && len(got) != 0 {
+				t.Fatalf("dry run saved %d todos", len(got))
+			}
+			if dryRun {
+				return
+			}
+			child, err := svc.Get(ctx, results[importBatchSize].ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if child.ParentID == nil || *child.ParentID != results[0].ID {
+				t.Errorf("child ParentID = %v, want %d", child.ParentID, results[0].ID)
+			}
+			if len(child.Tags) != 1 || child.Tags[0].Name != "home" {
+				t.Errorf("child Tags = %v, want home", child.Tags)
+			}
+		})
+	}
+}
+
+func TestImportReadErrorKeepsEarlierBatches(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := newUser(t, repo, "alice@example.com")
+	file := "[" + strings.Repeat(`{"title":"a"},`, importBatchSize+1) + `{"title":`
+
+	results, err := svc.Import(ctx, ImportRequest{Format: FormatJSON}, strings.NewReader(file))
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Import error = %v, want %v", err, ErrValidation)
+	}
+	if len(results) != importBatchSize {
+		t.Errorf("got %d results, want the %d of the first batch", len(results), importBatchSize)
+	}
+	page, err := svc.List(ctx, ListOptions{Limit: MaxPageSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Todos) != importBatchSize {
+		t.Errorf("saved %d todos, want the %d of the first batch", len(page.Todos), importBatchSize)
+	}
+}
+
+// statuses returns the statuses of results separated by spaces.
+func statuses(results []ImportResult) string {
+	var s []string
+	for _, res := range results {
+		s = append(s, string(res.Status))
+	}
+	return strings.Join(s, " ")
+}
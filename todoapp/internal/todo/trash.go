@@ -0,0 +1,53 @@
+package todo
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// TrashPurger periodically and permanently deletes the todos that have
+// been in the trash for longer than a retention period.
+type TrashPurger struct {
+	repo      Repository
+	broker    *Broker
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewTrashPurger creates a purger that runs every interval and removes
+// todos trashed more than retention ago, publishing their purge to
+// broker.
+func NewTrashPurger(repo Repository, broker *Broker, retention, interval time.Duration) *TrashPurger {
+	return &TrashPurger{repo: repo, broker: broker, retention: retention, interval: interval}
+}
+
+// Run purges the trash until ctx is cancelled.
+func (p *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	n, err := publishing(ctx, p.broker, func(ctx context.Context) (int, error) {
+		return p.repo.PurgeTrash(ctx, time.Now().Add(-p.retention))
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to purge trash: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		log.Printf("Purged %d todos from the trash", n)
+	}
+}
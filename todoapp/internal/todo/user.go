@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// minPasswordLength is the shortest password accepted, in characters.
+const minPasswordLength = 8
+
+// User is an account that owns todos, lists and tags.
+type User struct {
+	ID        int       `json:"id" example:"1"`
+	Email     string    `json:"email" example:"ada@example.com"`
+	CreatedAt time.Time `json:"created_at" example:"2023-01-01T00:00:00Z"`
+	// PasswordHash is the bcrypt hash of the user's password.
+	PasswordHash string `json:"-"`
+}
+
+// CredentialsRequest represents the request body for registering and
+// logging in.
+type CredentialsRequest struct {
+	Email    string `json:"email" example:"ada@example.com"`
+	Password string `json:"password" example:"correct horse battery staple"`
+}
+
+// TokenResponse carries an access token, to be sent as
+// "Authorization: Bearer <token>".
+type TokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at" example:"2023-01-02T00:00:00Z"`
+	User      User      `json:"user"`
+}
+
+type userKey struct{}
+
+// ContextWithUser returns a context carrying the id of the authenticated
+// user. Repository operations are scoped to that user.
+func ContextWithUser(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserFromContext returns the authenticated user set by ContextWithUser.
+func UserFromContext(ctx context.Context) (int, bool) {
+	id, ok := ctx.Value(userKey{}).(int)
+	return id, ok
+}
+
+// currentUser returns the authenticated user, or ErrUnauthorized if
+// there is none.
+func currentUser(ctx context.Context) (int, error) {
+	id, ok := UserFromContext(ctx)
+	if !ok {
+		return 0, ErrUnauthorized
+	}
+	return id, nil
+}
+
+// normalizeEmail trims and lower-cases email and checks it is a plain
+// address.
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", validationError("email must be a valid address")
+	}
+	return email, nil
+}
+
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return validationError("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > 72 {
+		// bcrypt only looks at the first 72 bytes.
+		return validationError("password must be at most 72 bytes")
+	}
+	return nil
+}
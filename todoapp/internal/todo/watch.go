@@ -0,0 +1,93 @@
+package todo
+
+import "time"
+
+const (
+	// maxReplay is the most events Watch replays from the audit log. A
+	// watcher further behind gets an EventReset instead.
+	maxReplay = 1000
+
+	// commitGrace is how long Watch waits for an event whose id is
+	// missing before taking it to be rolled back or hidden from the
+	// watcher.
+	commitGrace = time.Minute
+)
+
+// watchCursor tracks which events a watcher is done with. Event ids are
+// taken in the order changes are made but published in the order they
+// commit, so an event may arrive after events with higher ids; the
+// cursor is the highest id up to which every event is done with.
+type watchCursor struct {
+	// low is the cursor, and above holds the ids greater than low of the
+	// events done with. high is the greatest id done with.
+	low   int64
+	above map[int64]bool
+	high  int64
+
+	// missingSince is when the event after low was first missing, or
+	// zero if none is. The events up to missingUntil have been missing
+	// as long.
+	missingSince time.Time
+	missingUntil int64
+
+	// recent holds the ids of the latest events done with, oldest
+	// first, and seen holds the same ids.
+	recent []int64
+	seen   map[int64]bool
+}
+
+func newWatchCursor(low int64) *watchCursor {
+	return &watchCursor{
+		low:   low,
+		high:  low,
+		above: make(map[int64]bool),
+		seen:  make(map[int64]bool),
+	}
+}
+
+// done reports whether the event with the given id was done with
+// recently, so that a watcher gets it once although the replay and the
+// subscription both deliver it. An event with an id below the cursor
+// that is not done with was given up on, and is passed on if it arrives.
+func (c *watchCursor) done(id int64) bool {
+	return c.seen[id]
+}
+
+// pass marks the event with the given id done with at now, and returns
+// the cursor. An event missing for commitGrace is given up on.
+func (c *watchCursor) pass(id int64, now time.Time) int64 {
+	c.seen[id] = true
+	c.recent = append(c.recent, id)
+	if len(c.recent) > recentEvents {
+		delete(c.seen, c.recent[0])
+		c.recent = c.recent[1:]
+	}
+	if id > c.low {
+		c.above[id] = true
+		c.high = max(c.high, id)
+	}
+
+	for {
+		if c.above[c.low+1] {
+			c.low++
+			delete(c.above, c.low)
+			continue
+		}
+		if len(c.above) == 0 {
+			c.missingSince = time.Time{}
+			return c.low
+		}
+		if c.missingSince.IsZero() || c.low >= c.missingUntil {
+			c.missingSince, c.missingUntil = now, c.high
+			return c.low
+		}
+		if now.Sub(c.missingSince) < commitGrace {
+			return c.low
+		}
+		for c.low < c.missingUntil {
+			c.low++
+			delete(c.above, c.low)
+		}
+		c.missingSince = time.Time{}
+	}
+}
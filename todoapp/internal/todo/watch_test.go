@@ -0,0 +1,227 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWatchCursor(t *testing.T) {
+	type step struct {
+		id int64
+		// at is when the event arrives, after the first one.
+		at         time.Duration
+		wantCursor int64
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "in order",
+			steps: []step{{1, 0, 1}, {2, 0, 2}, {3, 0, 3}},
+		},
+		{
+			name:  "out of order",
+			steps: []step{{2, 0, 0}, {3, 0, 0}, {1, 0, 3}},
+		},
+		{
+			name:  "missing event given up on",
+			steps: []step{{2, 0, 0}, {3, 30 * time.Second, 0}, {4, commitGrace, 4}},
+		},
+		{
+			name:  "given up event arrives",
+			steps: []step{{2, 0, 0}, {3, commitGrace, 3}, {1, commitGrace, 3}, {4, commitGrace, 4}},
+		},
+		{
+			name: "later gap waits its own grace",
+			steps: []step{
+				{2, 0, 0}, {5, 50 * time.Second, 0}, {1, 55 * time.Second, 2},
+				{6, 70 * time.Second, 2}, {7, 55*time.Second + commitGrace, 7},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			c := newWatchCursor(0)
+			for _, s := range tt.steps {
+				if c.done(s.id) {
+					t.Fatalf("event %d is done before it arrived", s.id)
+				}
+				if got := c.pass(s.id, start.Add(s.at)); got != s.wantCursor {
+					t.Errorf("cursor after event %d at %v = %d, want %d", s.id, s.at, got, s.wantCursor)
+				}
+				if !c.done(s.id) {
+					t.Errorf("event %d is not done after it arrived", s.id)
+				}
+			}
+		})
+	}
+}
+
+// watched is an event a Watch sent, with its cursor.
+type watched struct {
+	id     int64
+	action EventAction
+	cursor int64
+}
+
+func (w watched) String() string {
+	return fmt.Sprintf("%d %s @%d", w.id, w.action, w.cursor)
+}
+
+// watch runs svc.Watch from after for the user in ctx, calls during once
+// the watch is subscribed to broker, and returns the first n events sent.
+func watch(t *testing.T, svc Service, broker *Broker, ctx context.Context, after int64, n int, during func()) []watched {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	events := make(chan watched)
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.Watch(ctx, after, func(e Event, cursor int64) error {
+			select {
+			case events <- watched{e.ID, e.Action, cursor}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	for !subscribed(broker) {
+		time.Sleep(time.Millisecond)
+	}
+	during()
+
+	var got []watched
+	for len(got) < n {
+		select {
+		case e := <-events:
+			got = append(got, e)
+		case err := <-done:
+			t.Fatalf("Watch ended after %v: %v", got, err)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Watch error = %v, want %v", err, context.Canceled)
+	}
+	return got
+}
+
+func subscribed(b *Broker) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs) > 0
+}
+
+func TestWatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		after int64
+		// during runs once the watch is subscribed, as alice and bob.
+		during func(t *testing.T, svc Service, broker *Broker, alice, bob context.Context)
+		want   []watched
+	}{
+		{
+			name:   "replay",
+			after:  1,
+			during: func(*testing.T, Service, *Broker, context.Context, context.Context) {},
+			want:   []watched{{2, EventCreate, 2}, {3, EventCreate, 3}},
+		},
+		{
+			name:  "live",
+			after: 0,
+			during: func(t *testing.T, svc Service, _ *Broker, alice, _ context.Context) {
+				createTodo(t, svc, alice, "d")
+			},
+			want: []watched{{4, EventCreate, 4}},
+		},
+		{
+			name:  "replay then live",
+			after: 2,
+			during: func(t *testing.T, svc Service, _ *Broker, alice, _ context.Context) {
+				createTodo(t, svc, alice, "d")
+			},
+			want: []watched{{3, EventCreate, 3}, {4, EventCreate, 4}},
+		},
+		{
+			name:  "hidden events move the cursor",
+			after: 0,
+			during: func(t *testing.T, svc Service, _ *Broker, alice, bob context.Context) {
+				createTodo(t, svc, bob, "secret")
+				createTodo(t, svc, alice, "d")
+			},
+			want: []watched{{5, EventCreate, 5}},
+		},
+		{
+			name:  "out of commit order",
+			after: 0,
+			during: func(t *testing.T, _ Service, broker *Broker, _, _ context.Context) {
+				broker.Publish(Event{ID: 5, ActorID: 1, Action: EventUpdate})
+				broker.Publish(Event{ID: 4, ActorID: 1, Action: EventUpdate})
+			},
+			want: []watched{{5, EventUpdate, 3}, {4, EventUpdate, 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMemoryRepository()
+			broker := NewBroker()
+			svc := NewService(repo, broker)
+			alice := newUser(t, repo, "alice@example.com")
+			bob := newUser(t, repo, "bob@example.com")
+			for _, title := range []string{"a", "b", "c"} {
+				createTodo(t, svc, alice, title)
+			}
+
+			got := watch(t, svc, broker, alice, tt.after, len(tt.want), func() {
+				tt.during(t, svc, broker, alice, bob)
+			})
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatchReset(t *testing.T) {
+	repo := NewMemoryRepository()
+	broker := NewBroker()
+	svc := NewService(repo, broker)
+	ctx := newUser(t, repo, "alice@example.com")
+	for i := range maxReplay + 2 {
+		createTodo(t, svc, ctx, fmt.Sprint(i))
+	}
+
+	latest := int64(maxReplay + 2)
+	got := watch(t, svc, broker, ctx, 1, 2, func() {
+		createTodo(t, svc, ctx, "after the reset")
+	})
+	want := []watched{{0, EventReset, latest}, {latest + 1, EventCreate, latest + 1}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWatchInvalid(t *testing.T) {
+	svc, repo := newTestService(t)
+	ctx := newUser(t, repo, "alice@example.com")
+	err := svc.Watch(ctx, -1, func(Event, int64) error { return nil })
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("Watch error = %v, want %v", err, ErrValidation)
+	}
+}
+
+func createTodo(t *testing.T, svc Service, ctx context.Context, title string) Todo {
+	t.Helper()
+	todo, err := svc.Create(ctx, CreateTodoRequest{Title: title})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}